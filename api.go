@@ -0,0 +1,297 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ErrNotFound indicates that no sockets match the request.
+	ErrNotFound = Error("not found")
+)
+
+// apiServer serves the sockets of the manager over HTTP as JSON.
+// All endpoints are read-only unless the kill policy enables killing.
+type apiServer struct {
+	pm *ProcessManager
+	// hosts are the Host headers the API accepts.
+	hosts hostAllowlist
+	// token is the bearer token required by every request, empty disables authentication.
+	token string
+	kill  killPolicy
+	// audit logs every request and kill decision.
+	audit *slog.Logger
+}
+
+// newAPIHandler returns the handler of the /api/v1 endpoints.
+func newAPIHandler(pm *ProcessManager, hosts hostAllowlist, token string, kill killPolicy, audit *slog.Logger) http.Handler {
+	api := &apiServer{pm: pm, hosts: hosts, token: token, kill: kill, audit: audit}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/sockets", api.handleSockets)
+	mux.HandleFunc("GET /api/v1/ports/{port}", api.handlePort)
+	mux.HandleFunc("GET /api/v1/processes/{pid}", api.handleProcess)
+	mux.HandleFunc("GET /api/v1/events", api.handleEvents)
+	mux.HandleFunc("POST /api/v1/processes/{pid}/kill", api.handleKill)
+
+	return api.auditRequests(api.checkOrigin(api.authenticate(mux)))
+}
+
+// hostAllowlist holds the host names a server is reachable by on its port.
+// IP addresses and localhost are always allowed, DNS rebinding needs a host name.
+type hostAllowlist struct {
+	port  string
+	names []string
+}
+
+// newHostAllowlist allows the host of the listen address, the host name of
+// the machine and the extra names on the port of the listen address.
+func newHostAllowlist(addr string, names ...string) (hostAllowlist, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return hostAllowlist{}, fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+
+	hosts := hostAllowlist{port: port, names: names}
+	if host != "" {
+		hosts.names = append(hosts.names, host)
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts.names = append(hosts.names, hostname)
+	}
+
+	return hosts, nil
+}
+
+// allows reports whether the Host header names the server.
+func (h hostAllowlist) allows(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, "80"
+	}
+
+	if port != h.port {
+		return false
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return true
+	}
+
+	return slices.ContainsFunc(h.names, func(name string) bool { return strings.EqualFold(name, host) })
+}
+
+// checkOrigin rejects the requests to a host the API is not served on and the
+// requests from web pages of another origin, so a browser can't be turned against
+// the API by DNS rebinding or cross-site requests.
+func (a *apiServer) checkOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.hosts.allows(r.Host) {
+			writeAPIError(w, http.StatusForbidden, fmt.Errorf("host %s is not allowed", r.Host))
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if parsed, err := url.Parse(origin); err != nil || !strings.EqualFold(parsed.Host, r.Host) {
+				writeAPIError(w, http.StatusForbidden, fmt.Errorf("origin %s is not allowed", origin))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate rejects the requests without the bearer token.
+func (a *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleSockets lists the sockets. The protocol, port, process and listen query
+// parameters filter them the same way as the Options.
+func (a *apiServer) handleSockets(w http.ResponseWriter, r *http.Request) {
+	options, err := queryOptions(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	snapshot, err := a.pm.Snapshot(r.Context(), options...)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// handlePort lists the sockets bound to the port, the other filters of handleSockets apply as well.
+func (a *apiServer) handlePort(w http.ResponseWriter, r *http.Request) {
+	port, err := strconv.ParseUint(r.PathValue("port"), 10, 16)
+	if err != nil || port == 0 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid port: %s", r.PathValue("port")))
+		return
+	}
+
+	options, err := queryOptions(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	snapshot, err := a.pm.Snapshot(r.Context(), append(options, WithFilterPort(uint(port)))...)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(snapshot.Processes) == 0 {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("port %d: %w", port, ErrNotFound))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// handleProcess returns the details of a process using sockets.
+// The environment is never included.
+func (a *apiServer) handleProcess(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(r.PathValue("pid"))
+	if err != nil || pid <= 0 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid pid: %s", r.PathValue("pid")))
+		return
+	}
+
+	// The process may have exited since the last refresh.
+	details, err := a.pm.ProcessDetails(r.Context(), pid, false)
+	if err != nil || len(details.Sockets) == 0 {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("process %d: %w", pid, ErrNotFound))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleEvents streams the listener changes as server-sent events until the client disconnects.
+// Each event is named after the change kind and holds the ListenerChange as JSON.
+func (a *apiServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	previous, err := a.pm.Snapshot(ctx)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(a.pm.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			current, err := a.pm.Snapshot(ctx)
+			if err != nil {
+				return
+			}
+
+			for _, change := range DiffSnapshots(previous, current).Changes {
+				data, err := json.Marshal(change)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Kind, data)
+			}
+			previous = current
+
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// queryOptions converts the filter query parameters of the request to options.
+func queryOptions(r *http.Request) ([]Option, error) {
+	query := r.URL.Query()
+	options := make([]Option, 0, 4)
+
+	if protocol := query.Get("protocol"); protocol != "" {
+		options = append(options, WithFilterProtocol(protocol))
+	}
+
+	if value := query.Get("port"); value != "" {
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %s", value)
+		}
+		options = append(options, WithFilterPort(uint(port)))
+	}
+
+	if process := query.Get("process"); process != "" {
+		options = append(options, WithFilterProcess(process))
+	}
+
+	if value := query.Get("listen"); value != "" {
+		listen, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen: %s", value)
+		}
+		options = append(options, WithShowListenOnly(listen))
+	}
+
+	if _, err := parseOptions(options...); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// writeAPIError writes the error as a JSON response with the status code.
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+)
+
+const (
+	// ErrKillRefused indicates that the process must not be killed.
+	ErrKillRefused = Error("kill refused")
+)
+
+// kthreaddPID is the PID of the Linux kernel thread daemon, the parent of all kernel threads.
+const kthreaddPID = 2
+
+// killPolicy controls which processes may be killed over the API.
+type killPolicy struct {
+	enabled bool
+	// processes and users are the allowed process names and users. A process
+	// must match every non-empty list.
+	processes []string
+	users     []string
+	// dryRun checks the requests without killing anything.
+	dryRun bool
+}
+
+// check returns why the process must not be killed, or nil if it may be.
+func (p killPolicy) check(details ProcessDetails) error {
+	switch {
+	case !p.enabled:
+		return fmt.Errorf("%w: killing is disabled", ErrKillRefused)
+	case len(p.processes) > 0 && !slices.Contains(p.processes, details.Name):
+		return fmt.Errorf("%w: process %s is not allowed", ErrKillRefused, details.Name)
+	case len(p.users) > 0 && !slices.Contains(p.users, details.User):
+		return fmt.Errorf("%w: user %s is not allowed", ErrKillRefused, details.User)
+	}
+
+	return nil
+}
+
+// isKernelThread reports whether the process is a Linux kernel thread, which has
+// no executable and is kthreadd or one of its children.
+func isKernelThread(details ProcessDetails) bool {
+	if runtime.GOOS != "linux" || details.Exe != "" {
+		return false
+	}
+
+	return details.PID == kthreaddPID || (len(details.Parents) > 0 && details.Parents[0].PID == kthreaddPID)
+}
+
+// killResult is the response of the kill endpoint.
+type killResult struct {
+	PID    int    `json:"pid"`
+	Name   string `json:"name"`
+	User   string `json:"user,omitempty"`
+	DryRun bool   `json:"dry_run"`
+	Killed bool   `json:"killed"`
+}
+
+// handleKill kills a process using sockets if the kill policy allows it. Processes with
+// a KillRisk are always refused as there is no way to confirm them a second time.
+// The dry_run query parameter only checks the policy, as does the server in dry-run mode.
+func (a *apiServer) handleKill(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(r.PathValue("pid"))
+	if err != nil || pid <= 0 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid pid: %s", r.PathValue("pid")))
+		return
+	}
+
+	dryRun := a.kill.dryRun
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		requested, err := strconv.ParseBool(value)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid dry_run: %s", value))
+			return
+		}
+		dryRun = dryRun || requested
+	}
+
+	audit := a.audit.With(slog.Int("pid", pid), slog.Bool("dry_run", dryRun), slog.String("remote_addr", r.RemoteAddr))
+
+	details, err := a.pm.ProcessDetails(r.Context(), pid, false)
+	if err != nil {
+		audit.Warn("kill rejected", slog.String("reason", "not found"))
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("process %d: %w", pid, ErrNotFound))
+		return
+	}
+
+	audit = audit.With(slog.String("name", details.Name), slog.String("user", details.User))
+
+	if err := a.kill.check(details); err != nil {
+		audit.Warn("kill rejected", slog.String("reason", err.Error()))
+		writeAPIError(w, http.StatusForbidden, err)
+		return
+	}
+
+	if risk, ok := a.pm.KillRisk(r.Context(), pid); ok {
+		err := fmt.Errorf("%w: %s", ErrKillRefused, risk.Reason)
+		audit.Warn("kill rejected", slog.String("reason", err.Error()))
+		writeAPIError(w, http.StatusForbidden, err)
+		return
+	}
+
+	// Only the processes listed by the API may be killed.
+	if len(details.Sockets) == 0 {
+		audit.Warn("kill rejected", slog.String("reason", "no sockets"))
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("process %d: %w", pid, ErrNotFound))
+		return
+	}
+
+	result := killResult{PID: pid, Name: details.Name, User: details.User, DryRun: dryRun}
+
+	if dryRun {
+		audit.Info("kill allowed")
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if err := a.pm.KillProcess(r.Context(), pid, false); err != nil {
+		audit.Error("kill failed", slog.String("error", err.Error()))
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	audit.Info("killed")
+	result.Killed = true
+	writeJSON(w, http.StatusOK, result)
+}
+
+// auditRequests logs every request with its response status.
+func (a *apiServer) auditRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		a.audit.Info("request",
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.RequestURI()),
+			slog.Int("status", recorder.status),
+		)
+	})
+}
+
+// statusRecorder records the status code written to the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the original writer, so http.ResponseController can flush it.
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+func TestKillPolicyCheck(t *testing.T) {
+	node := ProcessDetails{PID: 100, Name: "node", User: "dev"}
+
+	tests := map[string]struct {
+		policy  killPolicy
+		details ProcessDetails
+		allowed bool
+	}{
+		"disabled": {
+			policy:  killPolicy{processes: []string{"node"}},
+			details: node,
+		},
+		"allowed by name": {
+			policy:  killPolicy{enabled: true, processes: []string{"python3", "node"}},
+			details: node,
+			allowed: true,
+		},
+		"name not allowed": {
+			policy:  killPolicy{enabled: true, processes: []string{"python3"}},
+			details: node,
+		},
+		"name is case-sensitive": {
+			policy:  killPolicy{enabled: true, processes: []string{"Node"}},
+			details: node,
+		},
+		"allowed by user": {
+			policy:  killPolicy{enabled: true, users: []string{"dev"}},
+			details: node,
+			allowed: true,
+		},
+		"user not allowed": {
+			policy:  killPolicy{enabled: true, users: []string{"root"}},
+			details: node,
+		},
+		"allowed by name and user": {
+			policy:  killPolicy{enabled: true, processes: []string{"node"}, users: []string{"dev"}},
+			details: node,
+			allowed: true,
+		},
+		"allowed name of another user": {
+			policy:  killPolicy{enabled: true, processes: []string{"node"}, users: []string{"root"}},
+			details: node,
+		},
+		"allowed user of another name": {
+			policy:  killPolicy{enabled: true, processes: []string{"python3"}, users: []string{"dev"}},
+			details: node,
+		},
+		"dry run checks the lists": {
+			policy:  killPolicy{enabled: true, processes: []string{"python3"}, dryRun: true},
+			details: node,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.check(tc.details)
+
+			switch {
+			case tc.allowed && err != nil:
+				t.Errorf("check() error = %v, want nil", err)
+			case !tc.allowed && !errors.Is(err, ErrKillRefused):
+				t.Errorf("check() error = %v, want %v", err, ErrKillRefused)
+			}
+		})
+	}
+}
+
+func TestIsKernelThread(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("kernel threads are detected on Linux only")
+	}
+
+	tests := map[string]struct {
+		details ProcessDetails
+		want    bool
+	}{
+		"kthreadd": {
+			details: ProcessDetails{PID: kthreaddPID, Name: "kthreadd"},
+			want:    true,
+		},
+		"child of kthreadd": {
+			details: ProcessDetails{PID: 50, Name: "kworker/0:1", Parents: []ProcessRef{{PID: kthreaddPID, Name: "kthreadd"}}},
+			want:    true,
+		},
+		"child of kthreadd with an executable": {
+			details: ProcessDetails{PID: 50, Name: "app", Exe: "/usr/bin/app", Parents: []ProcessRef{{PID: kthreaddPID}}},
+		},
+		"user process": {
+			details: ProcessDetails{PID: 50, Name: "sshd", Parents: []ProcessRef{{PID: 1, Name: "systemd"}}},
+		},
+		"grandchild of kthreadd": {
+			details: ProcessDetails{PID: 50, Parents: []ProcessRef{{PID: 40}, {PID: kthreaddPID}}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isKernelThread(tc.details); got != tc.want {
+				t.Errorf("isKernelThread() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleKill(t *testing.T) {
+	victim := startSleep(t)
+
+	// PID 1 is allowed by the policy, so the kill protection refuses it.
+	initName := "init"
+	if proc, err := process.NewProcess(1); err == nil {
+		if name, err := proc.Name(); err == nil {
+			initName = name
+		}
+	}
+
+	tests := map[string]struct {
+		policy     killPolicy
+		pid        int
+		query      string
+		protected  []string
+		wantStatus int
+		wantDryRun bool
+	}{
+		"dry run query": {
+			policy:     killPolicy{enabled: true, processes: []string{"sleep"}},
+			pid:        victim,
+			query:      "?dry_run=1",
+			wantStatus: http.StatusOK,
+			wantDryRun: true,
+		},
+		"dry run server": {
+			policy:     killPolicy{enabled: true, processes: []string{"sleep"}, dryRun: true},
+			pid:        victim,
+			wantStatus: http.StatusOK,
+			wantDryRun: true,
+		},
+		"dry run server can't be turned off": {
+			policy:     killPolicy{enabled: true, processes: []string{"sleep"}, dryRun: true},
+			pid:        victim,
+			query:      "?dry_run=false",
+			wantStatus: http.StatusOK,
+			wantDryRun: true,
+		},
+		"not allowed": {
+			policy:     killPolicy{enabled: true, processes: []string{"python3"}},
+			pid:        victim,
+			query:      "?dry_run=1",
+			wantStatus: http.StatusForbidden,
+		},
+		"protected": {
+			policy:     killPolicy{enabled: true, processes: []string{"sleep"}},
+			pid:        victim,
+			query:      "?dry_run=1",
+			protected:  []string{"sleep"},
+			wantStatus: http.StatusForbidden,
+		},
+		"init": {
+			policy:     killPolicy{enabled: true, processes: []string{initName}},
+			pid:        1,
+			query:      "?dry_run=1",
+			wantStatus: http.StatusForbidden,
+		},
+		"invalid dry run": {
+			policy:     killPolicy{enabled: true, processes: []string{"sleep"}},
+			pid:        victim,
+			query:      "?dry_run=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pm := &ProcessManager{
+				processes:  []Process{{PID: victim, Name: "sleep", Port: 8080, Protocol: ProtocolTCP, Status: StatusListen}},
+				protection: KillProtection{Processes: tc.protected},
+			}
+			api := &apiServer{pm: pm, kill: tc.policy, audit: slog.New(slog.DiscardHandler)}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/processes/"+strconv.Itoa(tc.pid)+"/kill"+tc.query, nil)
+			r.SetPathValue("pid", strconv.Itoa(tc.pid))
+			w := httptest.NewRecorder()
+
+			api.handleKill(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("handleKill() status = %d, want %d: %s", w.Code, tc.wantStatus, w.Body)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var result killResult
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if result.DryRun != tc.wantDryRun || result.Killed {
+				t.Errorf("handleKill() = %+v, want a dry run", result)
+			}
+		})
+	}
+
+	if alive, err := process.PidExists(int32(victim)); err != nil || !alive {
+		t.Errorf("dry runs killed the process")
+	}
+}
+
+// startSleep starts a process to kill, stopped when the test ends.
+func startSleep(t *testing.T) int {
+	t.Helper()
+
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	return cmd.Process.Pid
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// bandwidthHistorySize is the number of rate samples kept per socket for the sparklines.
+const bandwidthHistorySize = 30
+
+// WithBandwidthSampling returns an option that measures the network throughput of the
+// sockets from their byte counters on every refresh. The traffic of accepted connections
+// is also attributed to the listener they were accepted on.
+// Byte counters are only available for TCP sockets on Linux.
+func WithBandwidthSampling() ManagerOption {
+	return func(m *ProcessManager) { m.bandwidth = newBandwidthSampler() }
+}
+
+// byteCounters holds the byte counters of a socket at the last sample.
+type byteCounters struct {
+	sent     uint64
+	received uint64
+}
+
+// bandwidthSampler computes the socket rates between refreshes.
+type bandwidthSampler struct {
+	sampled  time.Time
+	counters map[socketKey]byteCounters
+	// history holds the recent total rates of each socket, the oldest first.
+	history map[socketKey][]float64
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{
+		counters: make(map[socketKey]byteCounters),
+		history:  make(map[socketKey][]float64),
+	}
+}
+
+// sample sets the rates of the processes from the byte counters change since the last sample.
+// The first sample only records the counters.
+func (b *bandwidthSampler) sample(processes []Process, now time.Time) {
+	elapsed := now.Sub(b.sampled).Seconds()
+	first := b.sampled.IsZero()
+	b.sampled = now
+
+	counters := make(map[socketKey]byteCounters, len(processes))
+	keys := make([]socketKey, len(processes))
+	listeners := make(map[portOwner]int)
+
+	for i := range processes {
+		p := &processes[i]
+
+		key, ok := processSocketKey(*p)
+		if !ok {
+			continue
+		}
+		keys[i] = key
+
+		if p.Status == StatusListen {
+			listeners[portOwner{pid: p.PID, port: p.Port}] = i
+		}
+
+		current := byteCounters{sent: p.BytesSent, received: p.BytesReceived}
+		counters[key] = current
+
+		previous, ok := b.counters[key]
+		if first || !ok || elapsed <= 0 {
+			continue
+		}
+
+		p.TxRate = float64(counterDelta(previous.sent, current.sent)) / elapsed
+		p.RxRate = float64(counterDelta(previous.received, current.received)) / elapsed
+	}
+
+	b.counters = counters
+
+	// Accepted connections share the local port with their listener.
+	for i, p := range processes {
+		if p.Status == StatusListen || !strings.HasPrefix(strings.ToUpper(p.Protocol), ProtocolTCP) {
+			continue
+		}
+		if listener, ok := listeners[portOwner{pid: p.PID, port: p.Port}]; ok && listener != i {
+			processes[listener].RxRate += p.RxRate
+			processes[listener].TxRate += p.TxRate
+		}
+	}
+
+	history := make(map[socketKey][]float64, len(processes))
+	for i, p := range processes {
+		if keys[i] == (socketKey{}) {
+			continue
+		}
+
+		rates := append(b.history[keys[i]], p.RxRate+p.TxRate)
+		if len(rates) > bandwidthHistorySize {
+			rates = rates[len(rates)-bandwidthHistorySize:]
+		}
+		history[keys[i]] = rates
+	}
+
+	b.history = history
+}
+
+// portOwner identifies the listener of a process by its port.
+type portOwner struct {
+	pid  int
+	port int
+}
+
+// counterDelta returns the increase of the counter, a smaller value means
+// the socket was replaced and the counter started over.
+func counterDelta(previous, current uint64) uint64 {
+	if current < previous {
+		return current
+	}
+
+	return current - previous
+}
+
+// processSocketKey returns the socket key of the process, false if its addresses can't be parsed.
+func processSocketKey(p Process) (socketKey, bool) {
+	local, err := netip.ParseAddrPort(p.LocalAddr)
+	if err != nil {
+		return socketKey{}, false
+	}
+
+	var remote netip.AddrPort
+	if p.RemoteAddr != "" {
+		remote, err = netip.ParseAddrPort(p.RemoteAddr)
+		if err != nil {
+			return socketKey{}, false
+		}
+	}
+
+	return newSocketKey(p.Protocol, local, remote), true
+}
+
+// BandwidthHistories returns the recent total rates in bytes per second of all the
+// sockets, the oldest first. It is empty unless bandwidth sampling is enabled.
+func (m *ProcessManager) BandwidthHistories() map[socketKey][]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.bandwidth == nil {
+		return nil
+	}
+
+	histories := make(map[socketKey][]float64, len(m.bandwidth.history))
+	for key, rates := range m.bandwidth.history {
+		histories[key] = slices.Clone(rates)
+	}
+
+	return histories
+}
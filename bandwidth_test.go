@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := map[string]struct {
+		previous uint64
+		current  uint64
+		want     uint64
+	}{
+		"unchanged": {previous: 100, current: 100, want: 0},
+		"increased": {previous: 100, current: 350, want: 250},
+		"reset":     {previous: 500, current: 40, want: 40},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := counterDelta(tc.previous, tc.current); got != tc.want {
+				t.Errorf("counterDelta(%d, %d) = %d, want %d", tc.previous, tc.current, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBandwidthSamplerSample(t *testing.T) {
+	sockets := func(sent, received uint64) []Process {
+		return []Process{
+			{PID: 1, Port: 8080, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:8080"},
+			{PID: 1, Port: 8080, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "127.0.0.1:8080", RemoteAddr: "127.0.0.1:50000", BytesSent: sent, BytesReceived: received},
+			{PID: 2, Port: 0, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "*:*"},
+		}
+	}
+
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	tests := map[string]struct {
+		counters  [][2]uint64
+		wantTx    []float64
+		wantRx    []float64
+		wantRates []float64
+	}{
+		"first sample": {
+			counters:  [][2]uint64{{100, 200}},
+			wantTx:    []float64{0, 0, 0},
+			wantRx:    []float64{0, 0, 0},
+			wantRates: []float64{0},
+		},
+		"rates attributed to the listener": {
+			counters:  [][2]uint64{{100, 200}, {300, 1200}},
+			wantTx:    []float64{100, 100, 0},
+			wantRx:    []float64{500, 500, 0},
+			wantRates: []float64{0, 600},
+		},
+		"counters started over": {
+			counters:  [][2]uint64{{100, 200}, {300, 1200}, {50, 0}},
+			wantTx:    []float64{25, 25, 0},
+			wantRx:    []float64{0, 0, 0},
+			wantRates: []float64{0, 600, 25},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sampler := newBandwidthSampler()
+
+			var processes []Process
+			for i, counters := range tc.counters {
+				processes = sockets(counters[0], counters[1])
+				sampler.sample(processes, start.Add(time.Duration(i)*2*time.Second))
+			}
+
+			for i, p := range processes {
+				if p.TxRate != tc.wantTx[i] || p.RxRate != tc.wantRx[i] {
+					t.Errorf("process %d rates = %v/%v, want %v/%v", i, p.TxRate, p.RxRate, tc.wantTx[i], tc.wantRx[i])
+				}
+			}
+
+			listener, _ := processSocketKey(processes[0])
+			if got := sampler.history[listener]; !slices.Equal(got, tc.wantRates) {
+				t.Errorf("listener history = %v, want %v", got, tc.wantRates)
+			}
+			if len(sampler.history) != 2 {
+				t.Errorf("history has %d sockets, want 2", len(sampler.history))
+			}
+		})
+	}
+}
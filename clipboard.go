@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Clipboard targets of the copy action.
+const (
+	copyPID     = "pid"
+	copyAddress = "address"
+	copyCommand = "command"
+	copyRowTSV  = "tsv"
+	copyRowJSON = "json"
+)
+
+// copyTargets maps the keys pressed after the copy key to the copy targets.
+var copyTargets = map[string]string{
+	"p": copyPID,
+	"a": copyAddress,
+	"c": copyCommand,
+	"r": copyRowTSV,
+	"j": copyRowJSON,
+}
+
+// copyTargetHelp lists the keys of the copy targets for the status bar.
+const copyTargetHelp = "Copy: [p] PID  [a] Address  [c] Command  [r] Row  [j] JSON  [Esc] Cancel"
+
+// copyText returns the text of the process to copy and its description.
+// The row is copied as the visible column values separated by tabs.
+func copyText(target string, process Process, columns []string) (string, string, error) {
+	switch target {
+	case copyPID:
+		return columnValue("pid", process), "PID", nil
+	case copyAddress:
+		return process.LocalAddr, "local address", nil
+	case copyCommand:
+		if process.Cmdline == "" {
+			return "", "", fmt.Errorf("command line of %s is not available", displayName(process.Name))
+		}
+		return process.Cmdline, "command line", nil
+	case copyRowTSV:
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = columnValue(column, process)
+		}
+		return strings.Join(values, "\t"), "row", nil
+	case copyRowJSON:
+		data, err := json.Marshal(process)
+		if err != nil {
+			return "", "", fmt.Errorf("marshal row: %w", err)
+		}
+		return string(data), "row as JSON", nil
+	default:
+		return "", "", fmt.Errorf("invalid copy target: %s", target)
+	}
+}
+
+// copyToClipboard writes the text to the clipboard. Over SSH, or when no local
+// clipboard is available, the terminal is asked to set its clipboard with OSC52.
+func copyToClipboard(text string) error {
+	if !isRemoteSession() && clipboard.WriteAll(text) == nil {
+		return nil
+	}
+
+	// The TUI renders to stdout from its own goroutine, the sequence goes
+	// straight to the terminal so it is not interleaved with a frame.
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return copyOSC52(os.Stderr, text)
+	}
+	defer tty.Close()
+
+	return copyOSC52(tty, text)
+}
+
+// isRemoteSession reports whether portman runs in an SSH session.
+func isRemoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_CLIENT") != ""
+}
+
+// copyOSC52 writes the OSC52 sequence setting the terminal clipboard in a single write,
+// wrapped for tmux and screen when running inside them.
+func copyOSC52(w io.Writer, text string) error {
+	sequence := osc52.New(text)
+
+	switch {
+	case os.Getenv("TMUX") != "":
+		sequence = sequence.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		sequence = sequence.Screen()
+	}
+
+	if _, err := io.WriteString(w, sequence.String()); err != nil {
+		return fmt.Errorf("write clipboard sequence: %w", err)
+	}
+
+	return nil
+}
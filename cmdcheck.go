@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/heartwilltell/scotty"
+)
+
+func newCheckCommand() *scotty.Command {
+	var (
+		policyPath string
+		format     string
+	)
+
+	return &scotty.Command{
+		Name:  "check",
+		Short: "Check listening ports against a policy",
+		Long:  "Compares listening ports with a policy file and exits with a non-zero code on violations.",
+		SetFlags: func(flags *scotty.FlagSet) {
+			flags.StringVar(&policyPath, "policy", "", "Path to the policy file (required)")
+			flags.StringVar(&format, "format", "text", "Report format: text, json or junit")
+			setConfigFlag(flags)
+			setColorFlag(flags)
+		},
+
+		Run: func(cmd *scotty.Command, args []string) error {
+			if policyPath == "" {
+				return fmt.Errorf("flag -policy is required")
+			}
+
+			switch format {
+			case "text", "json", "junit":
+			default:
+				return fmt.Errorf("invalid format: %s", format)
+			}
+
+			policy, err := LoadPolicy(policyPath)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			processManager, err := newListedProcessManager(ctx)
+			if err != nil {
+				return fmt.Errorf("new process manager: %w", err)
+			}
+			defer processManager.Stop()
+
+			processes, err := processManager.Processes(ctx)
+			if err != nil {
+				return fmt.Errorf("list processes: %w", err)
+			}
+
+			host, _ := os.Hostname()
+			violations := policy.Check(processes)
+
+			report := CheckReport{
+				Policy:     policyPath,
+				Host:       host,
+				Passed:     len(violations) == 0,
+				Violations: violations,
+			}
+			for _, process := range processes {
+				if isListener(process) {
+					report.Listeners++
+				}
+			}
+
+			var output string
+			switch format {
+			case "json":
+				output, err = RenderCheckReportJSON(report)
+			case "junit":
+				output, err = RenderCheckReportJUnit(report, policy)
+			default:
+				output = RenderCheckReportText(report)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(output)
+
+			if !report.Passed {
+				return fmt.Errorf("%w: %d violations", ErrPolicyViolation, len(violations))
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/heartwilltell/scotty"
+)
+
+func newDiffCommand() *scotty.Command {
+	var format string
+
+	return &scotty.Command{
+		Name:  "diff",
+		Short: "Compare listeners of two snapshots",
+		Long: "Reports listeners that appeared, disappeared, changed owner or bind address between two snapshots. " +
+			"With a single snapshot it is compared against the live state.",
+		SetFlags: func(flags *scotty.FlagSet) {
+			flags.StringVar(&format, "format", "text", "Report format: text, markdown or json")
+			setConfigFlag(flags)
+			setColorFlag(flags)
+		},
+
+		Run: func(cmd *scotty.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("usage: %s diff [flags] <before.json> [after.json]", cmdName)
+			}
+
+			switch format {
+			case "text", "markdown", "json":
+			default:
+				return fmt.Errorf("invalid format: %s", format)
+			}
+
+			before, err := LoadSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+
+			var after Snapshot
+
+			if len(args) == 2 {
+				// Only the theme is needed, the live state loads the config with the process manager.
+				if _, err := loadConfig(); err != nil {
+					return err
+				}
+
+				after, err = LoadSnapshot(args[1])
+				if err != nil {
+					return err
+				}
+			} else {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+
+				processManager, err := newListedProcessManager(ctx)
+				if err != nil {
+					return fmt.Errorf("new process manager: %w", err)
+				}
+				defer processManager.Stop()
+
+				after, err = processManager.Snapshot(ctx)
+				if err != nil {
+					return fmt.Errorf("take snapshot: %w", err)
+				}
+			}
+
+			diff := DiffSnapshots(before, after)
+
+			var output string
+			switch format {
+			case "markdown":
+				output, err = RenderMarkdownDiff(diff)
+			case "json":
+				output, err = RenderDiffJSON(diff)
+			default:
+				output = RenderDiffText(diff)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(output)
+
+			return nil
+		},
+	}
+}
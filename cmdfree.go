@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/heartwilltell/scotty"
+)
+
+func newFreeCommand() *scotty.Command {
+	var (
+		near      uint
+		portRange string
+		count     uint
+		protocol  string
+	)
+
+	return &scotty.Command{
+		Name:  "free",
+		Short: "Find free ports",
+		Long:  "Lists free ports near a requested port or in a range, skipping ports reserved by the kernel.",
+		SetFlags: func(flags *scotty.FlagSet) {
+			flags.UintVar(&near, "near", 0, "Suggest ports nearest to this port")
+			flags.StringVar(&portRange, "range", "", "Search only within this range, e.g. 3000-3999 (default: kernel ephemeral range)")
+			flags.UintVar(&count, "n", 5, "Number of ports to list")
+			flags.StringVar(&protocol, "proto", "tcp", "Protocol the port must be free for: tcp, udp or all")
+			setConfigFlag(flags)
+		},
+
+		Run: func(cmd *scotty.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			query := FreePortQuery{
+				Protocol: protocol,
+				Near:     int(near),
+				Count:    int(count),
+			}
+
+			if portRange != "" {
+				r, err := ParsePortRange(portRange)
+				if err != nil {
+					return err
+				}
+				query.Range = r
+			}
+
+			processManager, err := newConfiguredProcessManager(ctx)
+			if err != nil {
+				return fmt.Errorf("new process manager: %w", err)
+			}
+			defer processManager.Stop()
+
+			ports, err := processManager.FreePorts(ctx, query)
+			if err != nil {
+				return fmt.Errorf("find free ports: %w", err)
+			}
+
+			for _, port := range ports {
+				fmt.Println(port)
+			}
+
+			return nil
+		},
+	}
+}
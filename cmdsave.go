@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/heartwilltell/scotty"
+)
+
+func newSaveCommand() *scotty.Command {
+	var output string
+
+	return &scotty.Command{
+		Name:  "save",
+		Short: "Save a snapshot of used ports",
+		Long:  "Saves all sockets with their processes, the host name, time and portman version to a JSON file which can be opened later with -from-file.",
+		SetFlags: func(flags *scotty.FlagSet) {
+			flags.StringVar(&output, "o", "", "Output file (default: portman-<host>-<time>.json, - for stdout)")
+			setConfigFlag(flags)
+			setColorFlag(flags)
+		},
+
+		Run: func(cmd *scotty.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			processManager, err := newListedProcessManager(ctx)
+			if err != nil {
+				return fmt.Errorf("new process manager: %w", err)
+			}
+			defer processManager.Stop()
+
+			snapshot, err := processManager.Snapshot(ctx)
+			if err != nil {
+				return fmt.Errorf("take snapshot: %w", err)
+			}
+
+			if output == "-" {
+				return WriteSnapshot(os.Stdout, snapshot)
+			}
+
+			if output == "" {
+				output = defaultSnapshotPath(snapshot)
+			}
+
+			if err := SaveSnapshot(output, snapshot); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Saved %d sockets to %s\n", len(snapshot.Processes), output)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/heartwilltell/scotty"
+)
+
+// serverShutdownTimeout is how long the servers wait for the requests in flight on shutdown.
+const serverShutdownTimeout = 5 * time.Second
+
+func newServeCommand() *scotty.Command {
+	var (
+		metricsAddr string
+		apiAddr     string
+		token       string
+		apiHosts    stringList
+		auditPath   string
+		kill        killPolicy
+	)
+
+	return &scotty.Command{
+		Name:  "serve",
+		Short: "Run headless and serve metrics or a JSON API",
+		Long: "Refreshes the sockets in the background and serves them as Prometheus metrics and over a " +
+			"JSON API until interrupted. Both can share an address. The API is read-only unless -allow-kill is set.",
+		SetFlags: func(flags *scotty.FlagSet) {
+			flags.StringVar(&metricsAddr, "metrics", "", "Address to serve Prometheus metrics on, such as :9100")
+			flags.StringVar(&apiAddr, "api", "", "Address to serve the JSON API on, such as 127.0.0.1:7070")
+			flags.StringVarE(&token, "token", "PORTMAN_API_TOKEN", "", "Bearer token required by the JSON API")
+			flags.Var(&apiHosts, "api-host", "Host name clients reach the JSON API by, besides the machine name, repeatable or comma separated")
+			flags.BoolVar(&kill.enabled, "allow-kill", false, "Allow killing processes over the JSON API")
+			flags.Var((*stringList)(&kill.processes), "kill-process", "Process name that may be killed, repeatable or comma separated")
+			flags.Var((*stringList)(&kill.users), "kill-user", "User whose processes may be killed, repeatable or comma separated")
+			flags.BoolVar(&kill.dryRun, "dry-run", false, "Check kill requests without killing anything")
+			flags.StringVar(&auditPath, "audit-log", "", "File to append the JSON audit log of the API requests to (default: stderr)")
+			setConfigFlag(flags)
+		},
+
+		Run: func(cmd *scotty.Command, args []string) error {
+			if metricsAddr == "" && apiAddr == "" {
+				return fmt.Errorf("flag -metrics or -api is required")
+			}
+
+			if kill.enabled {
+				if apiAddr == "" {
+					return fmt.Errorf("flag -allow-kill requires -api")
+				}
+				if len(kill.processes) == 0 && len(kill.users) == 0 {
+					return fmt.Errorf("flag -allow-kill requires -kill-process or -kill-user")
+				}
+				if token == "" {
+					return fmt.Errorf("flag -allow-kill requires -token")
+				}
+			}
+
+			var hosts hostAllowlist
+			if apiAddr != "" {
+				var err error
+				if hosts, err = newHostAllowlist(apiAddr, apiHosts...); err != nil {
+					return fmt.Errorf("flag -api: %w", err)
+				}
+			}
+
+			audit := os.Stderr
+			if auditPath != "" {
+				file, err := os.OpenFile(auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+				if err != nil {
+					return fmt.Errorf("open audit log: %w", err)
+				}
+				defer file.Close()
+				audit = file
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// Later refresh failures are exported by the metrics and retried on the next tick.
+			processManager, err := newListedProcessManager(ctx)
+			if err != nil {
+				return fmt.Errorf("new process manager: %w", err)
+			}
+			defer processManager.Stop()
+
+			muxes := make(map[string]*http.ServeMux)
+			handle := func(addr, pattern string, handler http.Handler) {
+				if muxes[addr] == nil {
+					muxes[addr] = http.NewServeMux()
+				}
+				muxes[addr].Handle(pattern, handler)
+			}
+
+			if metricsAddr != "" {
+				handle(metricsAddr, "GET /metrics", metricsHandler(processManager))
+				fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics\n", metricsAddr)
+			}
+
+			if apiAddr != "" {
+				handle(apiAddr, "/api/", newAPIHandler(processManager, hosts, token, kill, slog.New(slog.NewJSONHandler(audit, nil))))
+				fmt.Fprintf(os.Stderr, "Serving the API on %s/api/v1\n", apiAddr)
+				if token == "" && !isLoopbackAddr(apiAddr) {
+					fmt.Fprintln(os.Stderr, "Warning: the API is reachable from the network without a token")
+				}
+			}
+
+			servers := make([]*http.Server, 0, len(muxes))
+			for addr, mux := range muxes {
+				servers = append(servers, &http.Server{
+					Addr:              addr,
+					Handler:           mux,
+					ReadHeaderTimeout: 10 * time.Second,
+					// Event streams end with the context.
+					BaseContext: func(net.Listener) context.Context { return ctx },
+				})
+			}
+
+			return serve(ctx, servers...)
+		},
+	}
+}
+
+// serve runs the servers until the context is done or one of them fails,
+// then shuts them all down gracefully.
+func serve(ctx context.Context, servers ...*http.Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errsOnce sync.Once
+		serveErr error
+	)
+
+	for _, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errsOnce.Do(func() { serveErr = fmt.Errorf("serve %s: %w", server.Addr, err) })
+				cancel()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer shutdownCancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			errsOnce.Do(func() { serveErr = fmt.Errorf("shut down server %s: %w", server.Addr, err) })
+		}
+	}
+
+	wg.Wait()
+
+	return serveErr
+}
+
+// isLoopbackAddr reports whether the listen address only accepts local connections.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// stringList is a flag which can be repeated or hold comma separated values.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	for item := range strings.SplitSeq(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return errors.New("empty value")
+		}
+		*l = append(*l, item)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tableColumn describes the layout of a TUI table column.
+type tableColumn struct {
+	title string
+	// min is the narrowest width of the column.
+	min int
+	// weight is the share of the spare terminal width the column gets.
+	weight int
+}
+
+// tableColumns maps the column names used in the config to their layout.
+var tableColumns = map[string]tableColumn{
+	"pid":      {title: "PID", min: 6, weight: 0},
+	"protocol": {title: "Protocol", min: 8, weight: 0},
+	"port":     {title: "Port", min: 6, weight: 0},
+	"service":  {title: "Service", min: 10, weight: 1},
+	"status":   {title: "Status", min: 12, weight: 1},
+	"address":  {title: "Local Address", min: 18, weight: 0},
+	"process":  {title: "Process", min: 18, weight: 6},
+	"remote":   {title: "Remote Address", min: 18, weight: 0},
+	"user":     {title: "User", min: 10, weight: 1},
+	"command":  {title: "Command", min: 20, weight: 6},
+	"cpu":      {title: "CPU", min: 6, weight: 0},
+	"memory":   {title: "Memory", min: 8, weight: 0},
+	"recvq":    {title: "Recv-Q", min: 7, weight: 0},
+	"sendq":    {title: "Send-Q", min: 7, weight: 0},
+	"retrans":  {title: "Retrans", min: 7, weight: 0},
+	"rtt":      {title: "RTT", min: 8, weight: 0},
+	"rx":       {title: "RX/s", min: 9, weight: 0},
+	"tx":       {title: "TX/s", min: 9, weight: 0},
+	"traffic":  {title: "Traffic", min: 12, weight: 0},
+	// The history columns draw sparklines of the process samples.
+	"conns":          {title: "Conns", min: 12, weight: 0},
+	"cpu_history":    {title: "CPU History", min: 12, weight: 0},
+	"memory_history": {title: "Mem History", min: 12, weight: 0},
+}
+
+// columnOrder lists all the columns in the order they are offered by the column chooser.
+var columnOrder = []string{
+	"pid", "protocol", "port", "service", "status", "address", "remote",
+	"process", "user", "command", "cpu", "memory", "recvq", "sendq", "retrans", "rtt",
+	"rx", "tx", "traffic", "conns", "cpu_history", "memory_history",
+}
+
+// defaultColumns lists the columns shown when the config does not set them.
+var defaultColumns = []string{"pid", "protocol", "port", "service", "status", "address", "process"}
+
+// validateColumns reports unknown and repeated column names.
+func validateColumns(names []string) error {
+	for i, name := range names {
+		if _, ok := tableColumns[name]; !ok {
+			return fmt.Errorf("unknown column %q, available: %s", name, strings.Join(columnOrder, ", "))
+		}
+		if slices.Contains(names[:i], name) {
+			return fmt.Errorf("column %q is listed twice", name)
+		}
+	}
+
+	return nil
+}
+
+// columnValue returns the plain text of the column for the process.
+// The history columns have no text value.
+func columnValue(column string, process Process) string {
+	switch column {
+	case "pid":
+		return strconv.Itoa(process.PID)
+	case "protocol":
+		return process.Protocol
+	case "port":
+		return strconv.Itoa(process.Port)
+	case "service":
+		return process.Service
+	case "status":
+		return process.Status
+	case "address":
+		return process.LocalAddr
+	case "remote":
+		return process.RemoteAddr
+	case "process":
+		return process.Name
+	case "user":
+		return process.User
+	case "command":
+		return process.Cmdline
+	case "cpu":
+		return fmt.Sprintf("%.1f%%", process.CPUPercent)
+	case "memory":
+		if process.MemoryRSS == 0 {
+			return ""
+		}
+		return formatBytes(process.MemoryRSS)
+	case "recvq":
+		return strconv.Itoa(process.RecvQ)
+	case "sendq":
+		return strconv.Itoa(process.SendQ)
+	case "retrans":
+		return strconv.Itoa(process.Retransmits)
+	case "rtt":
+		if process.RTT == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.2fms", process.RTT)
+	case "rx":
+		return formatRate(process.RxRate)
+	case "tx":
+		return formatRate(process.TxRate)
+	case "traffic":
+		return formatRate(process.RxRate + process.TxRate)
+	default:
+		return ""
+	}
+}
+
+// compareColumn compares the processes by the column value. Numeric columns are
+// compared by number, addresses by IP and port, the others case-insensitively.
+func compareColumn(column string, a, b Process) int {
+	switch column {
+	case "pid":
+		return cmp.Compare(a.PID, b.PID)
+	case "port":
+		return cmp.Compare(a.Port, b.Port)
+	case "cpu":
+		return cmp.Compare(a.CPUPercent, b.CPUPercent)
+	case "memory":
+		return cmp.Compare(a.MemoryRSS, b.MemoryRSS)
+	case "recvq":
+		return cmp.Compare(a.RecvQ, b.RecvQ)
+	case "sendq":
+		return cmp.Compare(a.SendQ, b.SendQ)
+	case "retrans":
+		return cmp.Compare(a.Retransmits, b.Retransmits)
+	case "rtt":
+		return cmp.Compare(a.RTT, b.RTT)
+	case "rx":
+		return cmp.Compare(a.RxRate, b.RxRate)
+	case "tx":
+		return cmp.Compare(a.TxRate, b.TxRate)
+	case "traffic":
+		return cmp.Compare(a.RxRate+a.TxRate, b.RxRate+b.TxRate)
+	case "address", "remote":
+		x, errX := netip.ParseAddrPort(columnValue(column, a))
+		y, errY := netip.ParseAddrPort(columnValue(column, b))
+		if errX == nil && errY == nil {
+			return x.Compare(y)
+		}
+	}
+
+	return cmp.Compare(strings.ToLower(columnValue(column, a)), strings.ToLower(columnValue(column, b)))
+}
+
+// formatRate formats the bytes per second, e.g. "1.5K/s", zero as an empty string.
+func formatRate(rate float64) string {
+	if rate <= 0 {
+		return ""
+	}
+
+	return formatBytes(uint64(rate)) + "/s"
+}
+
+// formatBytes formats the byte count with a binary unit, e.g. "12.5M".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileName = "config.yaml"
+	// defaultRefreshInterval is how often the process list is refreshed.
+	defaultRefreshInterval = 5 * time.Second
+	// minRefreshInterval prevents refreshing so often that portman itself loads the system.
+	minRefreshInterval = 500 * time.Millisecond
+)
+
+// configPath is the config file set with the -config flag, empty means the default location.
+var configPath string
+
+// Config represents the user configuration file.
+type Config struct {
+	// Services maps ports to service names, overriding the built-in table.
+	// Keys are ports optionally followed by a protocol, e.g. "8080" or "5353/udp".
+	Services map[string]string `yaml:"services"`
+	// Defaults holds the TUI state at startup.
+	Defaults DefaultsConfig `yaml:"defaults"`
+	// Columns lists the visible TUI columns in display order.
+	Columns []string `yaml:"columns"`
+	// RefreshInterval is how often the process list is refreshed, e.g. "2s".
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	// History is how long the TUI keeps the samples of the history sparklines, e.g. "30m".
+	History time.Duration `yaml:"history"`
+	// Theme selects the base theme with "base" and overrides its colors.
+	Theme Theme `yaml:"theme"`
+	// Themes holds custom themes by name, usable as a base of the theme.
+	Themes map[string]Theme `yaml:"themes"`
+	// Keys maps TUI actions to the keys triggering them, an empty list disables the action.
+	Keys map[string][]string `yaml:"keys"`
+	// Protection selects the processes which are not killed without a second confirmation.
+	Protection ProtectionConfig `yaml:"protection"`
+}
+
+// DefaultsConfig holds the TUI state at startup.
+type DefaultsConfig struct {
+	// Filters lists the enabled filter toggles, "tcp" and "listen" when not set.
+	Filters *[]string `yaml:"filters"`
+	// Query is the initial search query.
+	Query string `yaml:"query"`
+	// Mode is the search mode of the query: "query", "regex" or "fuzzy".
+	Mode string `yaml:"mode"`
+}
+
+// ProtectionConfig selects the processes which are not killed without a second confirmation.
+type ProtectionConfig struct {
+	// Processes lists the protected process names, the defaultProtectedProcesses when not set.
+	// The parents of portman are always protected.
+	Processes *[]string `yaml:"processes"`
+	// Refuse refuses to kill the protected processes instead of asking again.
+	Refuse bool `yaml:"refuse"`
+}
+
+// configDir returns the portman directory under the XDG config directory.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, cmdName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".config", cmdName), nil
+}
+
+// defaultConfigPath returns the path of the config file under the XDG config directory.
+func defaultConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, configFileName), nil
+}
+
+// resolveConfigPath returns the path or the default config path if it is empty,
+// and whether the path was given explicitly.
+func resolveConfigPath(path string) (string, bool, error) {
+	if path != "" {
+		return path, true, nil
+	}
+
+	defaultPath, err := defaultConfigPath()
+	if err != nil {
+		return "", false, err
+	}
+
+	return defaultPath, false, nil
+}
+
+// LoadConfig reads and validates the config file at path or at the default location if path is empty.
+// A missing file at the default location is not an error and results in an empty config.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	path, explicit, err := resolveConfigPath(path)
+	if err != nil {
+		return config, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return config, nil
+		}
+		return config, fmt.Errorf("read config: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// validate reports the first invalid setting.
+func (c Config) validate() error {
+	if _, err := NewServiceRegistry(c.Services); err != nil {
+		return fmt.Errorf("services: %w", err)
+	}
+
+	if _, err := c.filterState(); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+
+	mode, err := parseSearchMode(c.Defaults.Mode)
+	if err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+
+	if _, err := newSearchQuery(c.Defaults.Query, mode); err != nil {
+		return fmt.Errorf("defaults: query: %w", err)
+	}
+
+	if err := validateColumns(c.Columns); err != nil {
+		return fmt.Errorf("columns: %w", err)
+	}
+
+	if c.RefreshInterval != 0 && c.RefreshInterval < minRefreshInterval {
+		return fmt.Errorf("refresh_interval: must be at least %s", minRefreshInterval)
+	}
+
+	if c.History != 0 && c.History < c.refreshInterval() {
+		return fmt.Errorf("history: must be at least the refresh interval %s", c.refreshInterval())
+	}
+
+	if _, err := c.theme(); err != nil {
+		return fmt.Errorf("theme: %w", err)
+	}
+
+	for name, theme := range c.Themes {
+		if _, err := resolveTheme(theme, c.Themes); err != nil {
+			return fmt.Errorf("themes: %s: %w", name, err)
+		}
+	}
+
+	if _, err := c.keyMap(); err != nil {
+		return fmt.Errorf("keys: %w", err)
+	}
+
+	return nil
+}
+
+// filterState returns the filter toggles enabled at startup.
+func (c Config) filterState() (filterState, error) {
+	if c.Defaults.Filters == nil {
+		return filterState{tcpOnly: true, listenOnly: true}, nil
+	}
+
+	return parseFilterState(*c.Defaults.Filters)
+}
+
+// columns returns the visible TUI columns.
+func (c Config) columns() []string {
+	if len(c.Columns) == 0 {
+		return defaultColumns
+	}
+
+	return c.Columns
+}
+
+// refreshInterval returns the process list refresh interval.
+func (c Config) refreshInterval() time.Duration {
+	if c.RefreshInterval == 0 {
+		return defaultRefreshInterval
+	}
+
+	return c.RefreshInterval
+}
+
+// history returns how long the history samples are kept.
+func (c Config) history() time.Duration {
+	if c.History == 0 {
+		return defaultHistory
+	}
+
+	return c.History
+}
+
+// killProtection returns the processes protected from being killed.
+func (c Config) killProtection() KillProtection {
+	protection := KillProtection{Processes: defaultProtectedProcesses, Refuse: c.Protection.Refuse}
+	if c.Protection.Processes != nil {
+		protection.Processes = *c.Protection.Processes
+	}
+
+	return protection
+}
+
+// theme returns the configured theme resolved against its base themes.
+func (c Config) theme() (Theme, error) {
+	return resolveTheme(c.Theme, c.Themes)
+}
+
+// keyMap returns the default key bindings with the configured overrides.
+func (c Config) keyMap() (keyMap, error) {
+	keys := defaultKeyMap()
+	if err := keys.apply(c.Keys); err != nil {
+		return keys, err
+	}
+
+	return keys, nil
+}
+
+// SaveConfigColumns sets the columns in the config file at path or at the default location
+// if path is empty. Other settings and comments of the file are preserved.
+func SaveConfigColumns(path string, columns []string) error {
+	path, _, err := resolveConfigPath(path)
+	if err != nil {
+		return err
+	}
+
+	var document yaml.Node
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	// A file without a value, such as "---" or only comments, is an empty mapping.
+	var comments []byte
+	switch {
+	case document.Kind == 0:
+		// The parser drops the comments of a file without nodes, they are kept as they are.
+		comments = bytes.TrimSpace(data)
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	case document.Content[0].Kind == yaml.ScalarNode && document.Content[0].Tag == "!!null":
+		null := document.Content[0]
+		document.Content[0] = &yaml.Node{
+			Kind:        yaml.MappingNode,
+			HeadComment: null.HeadComment,
+			LineComment: null.LineComment,
+			FootComment: null.FootComment,
+		}
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("parse config %s: top level is not a mapping", path)
+	}
+
+	var value yaml.Node
+	if err := value.Encode(columns); err != nil {
+		return fmt.Errorf("encode columns: %w", err)
+	}
+	value.Style = yaml.FlowStyle
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "columns" {
+			root.Content[i+1] = &value
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "columns"}, &value)
+	}
+
+	var output bytes.Buffer
+	if len(comments) > 0 {
+		output.Write(comments)
+		output.WriteString("\n\n")
+	}
+
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, output.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	filters := []string{"udp", "exposed"}
+	invalidFilters := []string{"closed"}
+
+	tests := map[string]struct {
+		config  Config
+		wantErr string
+	}{
+		"empty": {},
+		"full": {
+			config: Config{
+				Services:        map[string]string{"8080": "api", "5353/udp": "mdns"},
+				Defaults:        DefaultsConfig{Filters: &filters, Query: "port:8080", Mode: "query"},
+				Columns:         []string{"port", "process"},
+				RefreshInterval: time.Second,
+				Keys:            map[string][]string{"kill": {"K"}},
+			},
+		},
+		"invalid service": {
+			config:  Config{Services: map[string]string{"http": "web"}},
+			wantErr: "services:",
+		},
+		"invalid filter": {
+			config:  Config{Defaults: DefaultsConfig{Filters: &invalidFilters}},
+			wantErr: "defaults:",
+		},
+		"invalid mode": {
+			config:  Config{Defaults: DefaultsConfig{Mode: "glob"}},
+			wantErr: "defaults:",
+		},
+		"invalid query": {
+			config:  Config{Defaults: DefaultsConfig{Query: "port:http"}},
+			wantErr: "defaults: query:",
+		},
+		"invalid regex": {
+			config:  Config{Defaults: DefaultsConfig{Query: "(", Mode: "regex"}},
+			wantErr: "defaults: query:",
+		},
+		"unknown column": {
+			config:  Config{Columns: []string{"port", "color"}},
+			wantErr: "columns:",
+		},
+		"column listed twice": {
+			config:  Config{Columns: []string{"port", "port"}},
+			wantErr: "columns:",
+		},
+		"refresh too often": {
+			config:  Config{RefreshInterval: 100 * time.Millisecond},
+			wantErr: "refresh_interval:",
+		},
+		"colliding keys": {
+			config:  Config{Keys: map[string][]string{"kill": {"q"}}},
+			wantErr: "keys:",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := map[string]struct {
+		path    string
+		want    time.Duration
+		wantErr bool
+	}{
+		"missing default file": {
+			path: "",
+		},
+		"missing file": {
+			path:    filepath.Join(dir, "missing.yaml"),
+			wantErr: true,
+		},
+		"empty file": {
+			path: write("empty.yaml", ""),
+		},
+		"refresh interval": {
+			path: write("refresh.yaml", "refresh_interval: 2s\n"),
+			want: 2 * time.Second,
+		},
+		"unknown setting": {
+			path:    write("unknown.yaml", "refresh: 2s\n"),
+			wantErr: true,
+		},
+		"invalid setting": {
+			path:    write("invalid.yaml", "refresh_interval: 1ms\n"),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := LoadConfig(tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig(%q) error = nil, want an error", tc.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig(%q) error = %v", tc.path, err)
+			}
+
+			if config.RefreshInterval != tc.want {
+				t.Errorf("LoadConfig(%q) refresh interval = %v, want %v", tc.path, config.RefreshInterval, tc.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,23 @@ package main
 import (
 	"strconv"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Labels of the kill confirm dialog buttons, also used to find them for mouse clicks.
+const (
+	confirmKillLabel   = "[ Kill ]"
+	confirmCancelLabel = "[ Cancel ]"
 )
 
+var confirmKillButtonStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("203"))
+
+var confirmCancelButtonStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240"))
+
 func displayName(name string) string {
 	if name == "" {
 		return "process"
@@ -12,7 +27,18 @@ func displayName(name string) string {
 	return name
 }
 
-func renderConfirmBox(target Process) string {
+// confirmationWord is what has to be typed to kill the protected process: its name,
+// or its PID when the name is unknown.
+func confirmationWord(target Process) string {
+	if target.Name == "" {
+		return strconv.Itoa(target.PID)
+	}
+	return target.Name
+}
+
+// renderConfirmBox renders the kill confirm dialog. For a protected process it shows
+// why, and on the second confirmation the name typed so far.
+func renderConfirmBox(target Process, risk KillRisk, riskConfirmed bool, typed string) string {
 	lines := []string{
 		"Are you sure you want to kill?",
 		"PID: " + strconv.Itoa(target.PID),
@@ -20,5 +46,19 @@ func renderConfirmBox(target Process) string {
 	if target.Name != "" {
 		lines = append(lines, "Process: "+target.Name)
 	}
+	if risk.Reason != "" {
+		lines = append(lines, "", warningStyle.Render("Protected: "+risk.Reason))
+		if riskConfirmed {
+			lines = append(lines,
+				warningStyle.Render("Type "+confirmationWord(target)+" and press Enter to kill it anyway"),
+				"> "+typed+"_",
+			)
+		} else {
+			lines = append(lines, warningStyle.Render("Killing it needs a second confirmation"))
+		}
+	}
+	lines = append(lines, "",
+		confirmKillButtonStyle.Render(confirmKillLabel)+"  "+confirmCancelButtonStyle.Render(confirmCancelLabel),
+	)
 	return confirmBoxStyle.Render(strings.Join(lines, "\n"))
 }
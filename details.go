@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// maxParentChain limits how many ancestors of a process are looked up.
+const maxParentChain = 16
+
+// ProcessRef identifies a process by PID and name.
+type ProcessRef struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+}
+
+// ProcessDetails holds the information about a process shown in the detail pane.
+// Fields which can't be read, e.g. due to permissions, are left empty.
+type ProcessDetails struct {
+	PID        int          `json:"pid"`
+	Name       string       `json:"name"`
+	Exe        string       `json:"exe,omitempty"`
+	Cmdline    string       `json:"cmdline,omitempty"`
+	User       string       `json:"user,omitempty"`
+	Cwd        string       `json:"cwd,omitempty"`
+	Environ    []string     `json:"environ,omitempty"`
+	StartTime  time.Time    `json:"start_time,omitzero"`
+	Parents    []ProcessRef `json:"parents,omitempty"`
+	Sockets    []Process    `json:"sockets"`
+	OpenFiles  int          `json:"open_files,omitempty"`
+	Threads    int          `json:"threads,omitempty"`
+	CPUPercent float64      `json:"cpu_percent,omitempty"`
+	MemoryRSS  uint64       `json:"memory_rss,omitempty"`
+	MemoryVMS  uint64       `json:"memory_vms,omitempty"`
+}
+
+// ProcessDetails returns the details of the process with its sockets.
+// The environment is read only when withEnv is set as it may contain secrets.
+// In snapshot mode only the saved socket information is available.
+func (m *ProcessManager) ProcessDetails(ctx context.Context, pid int, withEnv bool) (ProcessDetails, error) {
+	details := ProcessDetails{PID: pid}
+
+	m.mu.RLock()
+	for _, p := range m.processes {
+		if p.PID != pid {
+			continue
+		}
+		details.Sockets = append(details.Sockets, p)
+		details.Name, details.Cmdline, details.User = p.Name, p.Cmdline, p.User
+		details.CPUPercent, details.MemoryRSS = p.CPUPercent, p.MemoryRSS
+	}
+	m.mu.RUnlock()
+
+	if m.origin != nil {
+		if len(details.Sockets) == 0 {
+			return details, fmt.Errorf("process %d is not in the snapshot", pid)
+		}
+		return details, nil
+	}
+
+	proc, err := process.NewProcessWithContext(ctx, int32(pid))
+	if err != nil {
+		return details, fmt.Errorf("find process %d: %w", pid, err)
+	}
+
+	if name, err := proc.NameWithContext(ctx); err == nil {
+		details.Name = name
+	}
+	if exe, err := proc.ExeWithContext(ctx); err == nil {
+		details.Exe = exe
+	}
+	if cmdline, err := proc.CmdlineWithContext(ctx); err == nil {
+		details.Cmdline = cmdline
+	}
+	if user, err := proc.UsernameWithContext(ctx); err == nil {
+		details.User = user
+	}
+	if cwd, err := proc.CwdWithContext(ctx); err == nil {
+		details.Cwd = cwd
+	}
+	if created, err := proc.CreateTimeWithContext(ctx); err == nil {
+		details.StartTime = time.UnixMilli(created)
+	}
+	if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+		details.OpenFiles = int(fds)
+	}
+	if threads, err := proc.NumThreadsWithContext(ctx); err == nil {
+		details.Threads = int(threads)
+	}
+	if memory, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		details.MemoryRSS = memory.RSS
+		details.MemoryVMS = memory.VMS
+	}
+
+	if withEnv {
+		environ, err := proc.EnvironWithContext(ctx)
+		if err != nil {
+			return details, fmt.Errorf("read environment of process %d: %w", pid, err)
+		}
+		details.Environ = slices.DeleteFunc(environ, func(v string) bool { return v == "" })
+		if details.Environ == nil {
+			details.Environ = []string{}
+		}
+	}
+
+	details.Parents = parentChain(ctx, proc)
+
+	return details, nil
+}
+
+// parentChain returns the ancestors of the process, the closest first.
+func parentChain(ctx context.Context, proc *process.Process) []ProcessRef {
+	var parents []ProcessRef
+
+	for range maxParentChain {
+		ppid, err := proc.PpidWithContext(ctx)
+		if err != nil || ppid <= 0 || ppid == proc.Pid {
+			break
+		}
+
+		parent, err := process.NewProcessWithContext(ctx, ppid)
+		if err != nil {
+			break
+		}
+
+		name, _ := parent.NameWithContext(ctx)
+		parents = append(parents, ProcessRef{PID: int(ppid), Name: name})
+		proc = parent
+	}
+
+	return parents
+}
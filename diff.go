@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Change kinds reported by DiffSnapshots.
+const (
+	// ChangeAppeared is a listener present only in the second snapshot.
+	ChangeAppeared = "appeared"
+	// ChangeDisappeared is a listener present only in the first snapshot.
+	ChangeDisappeared = "disappeared"
+	// ChangeOwner is a listener whose owning processes changed.
+	ChangeOwner = "owner_changed"
+	// ChangeAddress is a listener whose bind addresses changed.
+	ChangeAddress = "address_changed"
+)
+
+// ListenerState describes the owners and bind addresses of a listener.
+type ListenerState struct {
+	PIDs      []int    `json:"pids"`
+	Processes []string `json:"processes"`
+	Addresses []string `json:"addresses"`
+}
+
+// Owners returns the owners in the "name (pid)" form.
+func (s ListenerState) Owners() string {
+	owners := make([]string, 0, len(s.PIDs))
+	for i, pid := range s.PIDs {
+		owners = append(owners, fmt.Sprintf("%s (%d)", displayName(s.Processes[i]), pid))
+	}
+	return strings.Join(owners, ", ")
+}
+
+// String returns the owners and the addresses of the listener.
+func (s ListenerState) String() string {
+	return s.Owners() + " on " + strings.Join(s.Addresses, ", ")
+}
+
+// ListenerChange represents a single difference between two snapshots.
+type ListenerChange struct {
+	Kind     string         `json:"kind"`
+	Protocol string         `json:"protocol"`
+	Port     int            `json:"port"`
+	Before   *ListenerState `json:"before,omitempty"`
+	After    *ListenerState `json:"after,omitempty"`
+}
+
+// Summary returns a short description of what changed.
+func (c ListenerChange) Summary() string {
+	switch c.Kind {
+	case ChangeAppeared:
+		return c.After.String()
+	case ChangeDisappeared:
+		return c.Before.String()
+	case ChangeOwner:
+		return c.Before.Owners() + " -> " + c.After.Owners()
+	case ChangeAddress:
+		return strings.Join(c.Before.Addresses, ", ") + " -> " + strings.Join(c.After.Addresses, ", ")
+	default:
+		return c.Kind
+	}
+}
+
+// SnapshotInfo identifies a snapshot in a diff.
+type SnapshotInfo struct {
+	Host      string    `json:"host"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SnapshotDiff is the result of comparing two snapshots.
+type SnapshotDiff struct {
+	Before  SnapshotInfo     `json:"before"`
+	After   SnapshotInfo     `json:"after"`
+	Changes []ListenerChange `json:"changes"`
+}
+
+type listenerKey struct {
+	protocol string
+	port     int
+}
+
+// DiffSnapshots compares listeners of two snapshots.
+// Listeners are identified by protocol and port, see isListener for what counts as a listener.
+func DiffSnapshots(before, after Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		Before:  SnapshotInfo{Host: before.Host, CreatedAt: before.CreatedAt},
+		After:   SnapshotInfo{Host: after.Host, CreatedAt: after.CreatedAt},
+		Changes: make([]ListenerChange, 0),
+	}
+
+	beforeListeners := groupListeners(before.Processes)
+	afterListeners := groupListeners(after.Processes)
+
+	keys := make([]listenerKey, 0, len(beforeListeners)+len(afterListeners))
+	for key := range beforeListeners {
+		keys = append(keys, key)
+	}
+	for key := range afterListeners {
+		if _, ok := beforeListeners[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].port != keys[j].port {
+			return keys[i].port < keys[j].port
+		}
+		return keys[i].protocol < keys[j].protocol
+	})
+
+	for _, key := range keys {
+		beforeState, inBefore := beforeListeners[key]
+		afterState, inAfter := afterListeners[key]
+
+		change := ListenerChange{Protocol: key.protocol, Port: key.port}
+		if inBefore {
+			change.Before = &beforeState
+		}
+		if inAfter {
+			change.After = &afterState
+		}
+
+		switch {
+		case !inBefore:
+			change.Kind = ChangeAppeared
+			diff.Changes = append(diff.Changes, change)
+		case !inAfter:
+			change.Kind = ChangeDisappeared
+			diff.Changes = append(diff.Changes, change)
+		default:
+			if !slices.Equal(beforeState.PIDs, afterState.PIDs) || !slices.Equal(beforeState.Processes, afterState.Processes) {
+				change.Kind = ChangeOwner
+				diff.Changes = append(diff.Changes, change)
+			}
+			if !slices.Equal(beforeState.Addresses, afterState.Addresses) {
+				change.Kind = ChangeAddress
+				diff.Changes = append(diff.Changes, change)
+			}
+		}
+	}
+
+	return diff
+}
+
+// groupListeners collects owners and addresses of every listener.
+func groupListeners(processes []Process) map[listenerKey]ListenerState {
+	type owner struct {
+		pid  int
+		name string
+	}
+
+	owners := make(map[listenerKey]map[owner]struct{})
+	addresses := make(map[listenerKey]map[string]struct{})
+
+	for _, process := range processes {
+		if !isListener(process) {
+			continue
+		}
+
+		key := listenerKey{protocol: strings.ToLower(process.Protocol), port: process.Port}
+		if owners[key] == nil {
+			owners[key] = make(map[owner]struct{})
+			addresses[key] = make(map[string]struct{})
+		}
+
+		owners[key][owner{pid: process.PID, name: process.Name}] = struct{}{}
+		addresses[key][listenerHost(process.LocalAddr)] = struct{}{}
+	}
+
+	listeners := make(map[listenerKey]ListenerState, len(owners))
+
+	for key, set := range owners {
+		sorted := make([]owner, 0, len(set))
+		for o := range set {
+			sorted = append(sorted, o)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].pid < sorted[j].pid })
+
+		state := ListenerState{
+			PIDs:      make([]int, 0, len(sorted)),
+			Processes: make([]string, 0, len(sorted)),
+			Addresses: make([]string, 0, len(addresses[key])),
+		}
+		for _, o := range sorted {
+			state.PIDs = append(state.PIDs, o.pid)
+			state.Processes = append(state.Processes, o.name)
+		}
+		for address := range addresses[key] {
+			state.Addresses = append(state.Addresses, address)
+		}
+		sort.Strings(state.Addresses)
+
+		listeners[key] = state
+	}
+
+	return listeners
+}
+
+// changeMarkers maps change kinds to the markers of the text report.
+var changeMarkers = map[string]string{
+	ChangeAppeared:    "+",
+	ChangeDisappeared: "-",
+	ChangeOwner:       "~",
+	ChangeAddress:     "~",
+}
+
+// RenderDiffText renders the diff for humans.
+func RenderDiffText(diff SnapshotDiff) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", describeSnapshot(diff.Before), describeSnapshot(diff.After))
+
+	if len(diff.Changes) == 0 {
+		b.WriteString("No listener changes.\n")
+		return b.String()
+	}
+
+	for _, change := range diff.Changes {
+		line := fmt.Sprintf("%s %-12s %-16s %s",
+			changeMarkers[change.Kind],
+			change.Protocol+"/"+strconv.Itoa(change.Port),
+			change.Kind,
+			change.Summary(),
+		)
+		b.WriteString(changeStyle(change.Kind).Render(line) + "\n")
+	}
+
+	return b.String()
+}
+
+// changeStyle returns the style of the text report line for the change kind.
+func changeStyle(kind string) lipgloss.Style {
+	switch kind {
+	case ChangeAppeared:
+		return successStyle
+	case ChangeDisappeared:
+		return failureStyle
+	default:
+		return warningStyle
+	}
+}
+
+// RenderDiffJSON renders the diff as indented JSON.
+func RenderDiffJSON(diff SnapshotDiff) (string, error) {
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal diff: %w", err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+func describeSnapshot(info SnapshotInfo) string {
+	if info.CreatedAt.IsZero() {
+		return info.Host
+	}
+	return info.Host + " @ " + info.CreatedAt.Format(time.DateTime)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	listener := func(pid int, name, protocol string, port int, host string) Process {
+		return Process{
+			PID:       pid,
+			Name:      name,
+			Port:      port,
+			Protocol:  protocol,
+			Status:    StatusListen,
+			LocalAddr: fmt.Sprintf("%s:%d", host, port),
+		}
+	}
+
+	nginx := listener(10, "nginx", ProtocolTCP, 80, "0.0.0.0")
+	postgres := listener(20, "postgres", ProtocolTCP, 5432, "127.0.0.1")
+	client := Process{PID: 30, Name: "curl", Port: 50000, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "127.0.0.1:50000"}
+
+	tests := map[string]struct {
+		before, after []Process
+		want          []string
+	}{
+		"no changes": {
+			before: []Process{nginx, postgres},
+			after:  []Process{postgres, nginx},
+			want:   []string{},
+		},
+		"appeared": {
+			before: []Process{nginx},
+			after:  []Process{nginx, postgres},
+			want:   []string{"appeared tcp/5432"},
+		},
+		"disappeared": {
+			before: []Process{nginx, postgres},
+			after:  []Process{nginx},
+			want:   []string{"disappeared tcp/5432"},
+		},
+		"owner changed": {
+			before: []Process{nginx},
+			after:  []Process{listener(11, "nginx", ProtocolTCP, 80, "0.0.0.0")},
+			want:   []string{"owner_changed tcp/80"},
+		},
+		"address changed": {
+			before: []Process{nginx},
+			after:  []Process{listener(10, "nginx", ProtocolTCP, 80, "127.0.0.1")},
+			want:   []string{"address_changed tcp/80"},
+		},
+		"owner and address changed": {
+			before: []Process{nginx},
+			after:  []Process{listener(40, "caddy", ProtocolTCP, 80, "127.0.0.1")},
+			want:   []string{"owner_changed tcp/80", "address_changed tcp/80"},
+		},
+		"protocols are separate listeners": {
+			before: []Process{nginx},
+			after:  []Process{nginx, listener(10, "nginx", ProtocolTCP6, 80, "::")},
+			want:   []string{"appeared tcp6/80"},
+		},
+		"connections are ignored": {
+			before: []Process{nginx},
+			after:  []Process{nginx, client},
+			want:   []string{},
+		},
+		"sorted by port": {
+			before: []Process{postgres},
+			after:  []Process{nginx},
+			want:   []string{"appeared tcp/80", "disappeared tcp/5432"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff := DiffSnapshots(Snapshot{Host: "before", Processes: tc.before}, Snapshot{Host: "after", Processes: tc.after})
+
+			got := make([]string, len(diff.Changes))
+			for i, change := range diff.Changes {
+				got[i] = fmt.Sprintf("%s %s/%d", change.Kind, change.Protocol, change.Port)
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("DiffSnapshots() = %v, want %v", got, tc.want)
+			}
+			if diff.Before.Host != "before" || diff.After.Host != "after" {
+				t.Errorf("DiffSnapshots() hosts = %s, %s, want before, after", diff.Before.Host, diff.After.Host)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshotsStates(t *testing.T) {
+	before := []Process{
+		{PID: 20, Name: "app", Port: 8080, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "127.0.0.1:8080"},
+		{PID: 10, Name: "app", Port: 8080, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "10.0.0.1:8080"},
+	}
+	after := []Process{
+		{PID: 10, Name: "app", Port: 8080, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:8080"},
+	}
+
+	diff := DiffSnapshots(Snapshot{Processes: before}, Snapshot{Processes: after})
+	if len(diff.Changes) != 2 {
+		t.Fatalf("DiffSnapshots() changes = %d, want 2", len(diff.Changes))
+	}
+
+	change := diff.Changes[1]
+	if change.Kind != ChangeAddress {
+		t.Fatalf("change kind = %s, want %s", change.Kind, ChangeAddress)
+	}
+	if want := []int{10, 20}; !slices.Equal(change.Before.PIDs, want) {
+		t.Errorf("before PIDs = %v, want %v", change.Before.PIDs, want)
+	}
+	if want := []string{"10.0.0.1", "127.0.0.1"}; !slices.Equal(change.Before.Addresses, want) {
+		t.Errorf("before addresses = %v, want %v", change.Before.Addresses, want)
+	}
+	if want := []string{"0.0.0.0"}; !slices.Equal(change.After.Addresses, want) {
+		t.Errorf("after addresses = %v, want %v", change.After.Addresses, want)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/netip"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Exposure classes of a bound address.
+const (
+	// ExposureLoopback is reachable only from the host itself.
+	ExposureLoopback = "loopback"
+	// ExposureLinkLocal is reachable from the local network segment.
+	ExposureLinkLocal = "link-local"
+	// ExposurePrivate is reachable from private networks.
+	ExposurePrivate = "private"
+	// ExposureWildcard is bound to all interfaces (0.0.0.0 or ::).
+	ExposureWildcard = "wildcard"
+	// ExposurePublic is reachable from public networks.
+	ExposurePublic = "public"
+	// ExposureUnknown is used when the address can't be parsed.
+	ExposureUnknown = "unknown"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598) not covered by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// ClassifyExposure returns the exposure class of the address.
+func ClassifyExposure(addr netip.Addr) string {
+	addr = addr.Unmap()
+
+	switch {
+	case !addr.IsValid():
+		return ExposureUnknown
+	case addr.IsUnspecified():
+		return ExposureWildcard
+	case addr.IsLoopback():
+		return ExposureLoopback
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast(), addr.IsInterfaceLocalMulticast():
+		return ExposureLinkLocal
+	case addr.IsPrivate(), sharedAddressSpace.Contains(addr):
+		return ExposurePrivate
+	default:
+		return ExposurePublic
+	}
+}
+
+// isExternallyReachable reports whether a socket with the exposure can be reached from other hosts.
+func isExternallyReachable(exposure string) bool {
+	return exposure != ExposureLoopback && exposure != ExposureUnknown
+}
+
+// exposureStyles maps exposure classes to the styles of the local address cells.
+var exposureStyles = map[string]lipgloss.Style{
+	ExposureLoopback:  lipgloss.NewStyle().Foreground(lipgloss.Color("78")),
+	ExposureLinkLocal: lipgloss.NewStyle().Foreground(lipgloss.Color("75")),
+	ExposurePrivate:   lipgloss.NewStyle().Foreground(lipgloss.Color("221")),
+	ExposureWildcard:  lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+	ExposurePublic:    lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true),
+}
+
+// renderExposure colors the text according to the exposure class.
+func renderExposure(text, exposure string) string {
+	style, ok := exposureStyles[exposure]
+	if !ok {
+		return text
+	}
+	return style.Render(text)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestClassifyExposure(t *testing.T) {
+	tests := map[string]struct {
+		addr netip.Addr
+		want string
+	}{
+		"invalid":              {addr: netip.Addr{}, want: ExposureUnknown},
+		"ipv4 wildcard":        {addr: netip.MustParseAddr("0.0.0.0"), want: ExposureWildcard},
+		"ipv6 wildcard":        {addr: netip.MustParseAddr("::"), want: ExposureWildcard},
+		"ipv4 loopback":        {addr: netip.MustParseAddr("127.0.0.1"), want: ExposureLoopback},
+		"ipv4 loopback range":  {addr: netip.MustParseAddr("127.0.1.1"), want: ExposureLoopback},
+		"ipv6 loopback":        {addr: netip.MustParseAddr("::1"), want: ExposureLoopback},
+		"ipv4-mapped loopback": {addr: netip.MustParseAddr("::ffff:127.0.0.1"), want: ExposureLoopback},
+		"ipv4 link-local":      {addr: netip.MustParseAddr("169.254.1.1"), want: ExposureLinkLocal},
+		"ipv6 link-local":      {addr: netip.MustParseAddr("fe80::1"), want: ExposureLinkLocal},
+		"ipv4 private":         {addr: netip.MustParseAddr("192.168.1.10"), want: ExposurePrivate},
+		"ipv4 private 10/8":    {addr: netip.MustParseAddr("10.0.0.1"), want: ExposurePrivate},
+		"ipv6 unique local":    {addr: netip.MustParseAddr("fd00::1"), want: ExposurePrivate},
+		"carrier-grade nat":    {addr: netip.MustParseAddr("100.64.0.1"), want: ExposurePrivate},
+		"ipv4-mapped private":  {addr: netip.MustParseAddr("::ffff:10.0.0.1"), want: ExposurePrivate},
+		"ipv4 public":          {addr: netip.MustParseAddr("8.8.8.8"), want: ExposurePublic},
+		"ipv6 public":          {addr: netip.MustParseAddr("2001:4860::8888"), want: ExposurePublic},
+		"next to carrier nat":  {addr: netip.MustParseAddr("100.128.0.1"), want: ExposurePublic},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ClassifyExposure(tc.addr); got != tc.want {
+				t.Errorf("ClassifyExposure(%v) = %s, want %s", tc.addr, got, tc.want)
+			}
+		})
+	}
+}
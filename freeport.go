@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ErrNoFreePorts indicates that no free ports were found in the requested range.
+	ErrNoFreePorts = Error("no free ports found")
+	// ErrInvalidPortRange indicates that a port range could not be parsed.
+	ErrInvalidPortRange = Error("invalid port range")
+)
+
+const (
+	// localPortRangePath holds the range of ephemeral ports the kernel hands out.
+	localPortRangePath = "/proc/sys/net/ipv4/ip_local_port_range"
+	// localReservedPortsPath holds ports the kernel never hands out automatically.
+	localReservedPortsPath = "/proc/sys/net/ipv4/ip_local_reserved_ports"
+)
+
+// defaultLocalPortRange is the Linux default used when the kernel setting can't be read.
+var defaultLocalPortRange = PortRange{Low: 32768, High: 60999}
+
+// PortRange represents an inclusive range of ports.
+type PortRange struct {
+	Low  int
+	High int
+}
+
+// Contains reports whether the port belongs to the range.
+func (r PortRange) Contains(port int) bool { return port >= r.Low && port <= r.High }
+
+// String returns the range in the "low-high" form.
+func (r PortRange) String() string { return fmt.Sprintf("%d-%d", r.Low, r.High) }
+
+// ParsePortRange parses a range in the "low-high" form or a single port.
+func ParsePortRange(s string) (PortRange, error) {
+	low, high, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		high = low
+	}
+
+	lowPort, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("%w: %q", ErrInvalidPortRange, s)
+	}
+
+	highPort, err := strconv.Atoi(strings.TrimSpace(high))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("%w: %q", ErrInvalidPortRange, s)
+	}
+
+	r := PortRange{Low: lowPort, High: highPort}
+	if r.Low < 1 || r.High > 65535 || r.Low > r.High {
+		return PortRange{}, fmt.Errorf("%w: %q", ErrInvalidPortRange, s)
+	}
+
+	return r, nil
+}
+
+// FreePortQuery describes which free ports to look for.
+type FreePortQuery struct {
+	// Protocol is "tcp", "udp" or "all" (free for both).
+	Protocol string
+	// Near is the preferred port, ports are returned ordered by distance from it.
+	// Zero means ports are returned in ascending order.
+	Near int
+	// Range limits the search. Zero value means the default range, which skips
+	// the kernel ephemeral ports when searching near a port outside of them.
+	Range PortRange
+	// Count is the number of ports to return.
+	Count int
+}
+
+// FreePorts returns free ports that match the query.
+// A port is considered free when the current snapshot has no socket on it
+// and a bind probe for the requested protocol succeeds.
+func (m *ProcessManager) FreePorts(ctx context.Context, query FreePortQuery) ([]int, error) {
+	if m.origin != nil {
+		return nil, ErrReadOnly
+	}
+
+	if query.Count <= 0 {
+		query.Count = 1
+	}
+
+	switch query.Protocol {
+	case "":
+		query.Protocol = ProtocolAll
+	case "tcp", "udp", ProtocolAll:
+	default:
+		return nil, fmt.Errorf("invalid protocol: %s", query.Protocol)
+	}
+
+	// Ephemeral ports may be taken by outgoing connections at any time.
+	var ephemeral PortRange
+	if query.Range == (PortRange{}) {
+		query.Range = defaultSearchRange(query.Near)
+		if local := localPortRange(); query.Near != 0 && !local.Contains(query.Near) {
+			ephemeral = local
+		}
+	}
+
+	if query.Near != 0 && !query.Range.Contains(query.Near) {
+		return nil, fmt.Errorf("port %d is outside of range %s", query.Near, query.Range)
+	}
+
+	processes, err := m.Processes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+
+	occupied := occupiedPorts(processes, query.Protocol)
+	reserved := localReservedPorts()
+
+	free := make([]int, 0, query.Count)
+
+	for _, port := range candidatePorts(query.Range, query.Near) {
+		if err := ctx.Err(); err != nil {
+			// The ports found so far are still free.
+			return free, err
+		}
+
+		if occupied[port] || reserved[port] || ephemeral.Contains(port) {
+			continue
+		}
+
+		if !probePort(ctx, query.Protocol, port) {
+			continue
+		}
+
+		free = append(free, port)
+		if len(free) == query.Count {
+			break
+		}
+	}
+
+	if len(free) == 0 {
+		return nil, ErrNoFreePorts
+	}
+
+	return free, nil
+}
+
+// defaultSearchRange returns the range to search when none was requested.
+// Without a preferred port the kernel ephemeral range is used,
+// otherwise the whole unprivileged range (or everything for privileged ports).
+func defaultSearchRange(near int) PortRange {
+	switch {
+	case near == 0:
+		return localPortRange()
+	case near < 1024:
+		return PortRange{Low: 1, High: 65535}
+	default:
+		return PortRange{Low: 1024, High: 65535}
+	}
+}
+
+// candidatePorts returns ports of the range in the order they should be probed.
+func candidatePorts(r PortRange, near int) []int {
+	ports := make([]int, 0, r.High-r.Low+1)
+
+	if near == 0 {
+		for port := r.Low; port <= r.High; port++ {
+			ports = append(ports, port)
+		}
+		return ports
+	}
+
+	ports = append(ports, near)
+	for distance := 1; near+distance <= r.High || near-distance >= r.Low; distance++ {
+		if near+distance <= r.High {
+			ports = append(ports, near+distance)
+		}
+		if near-distance >= r.Low {
+			ports = append(ports, near-distance)
+		}
+	}
+
+	return ports
+}
+
+// occupiedPorts returns the set of ports used by processes with the given protocol.
+func occupiedPorts(processes []Process, protocol string) map[int]bool {
+	occupied := make(map[int]bool, len(processes))
+
+	for _, process := range processes {
+		proto := strings.ToLower(process.Protocol)
+		if protocol != ProtocolAll && !strings.HasPrefix(proto, protocol) {
+			continue
+		}
+		occupied[process.Port] = true
+	}
+
+	return occupied
+}
+
+// probePort reports whether the port can be bound for the given protocol.
+func probePort(ctx context.Context, protocol string, port int) bool {
+	var lc net.ListenConfig
+
+	addr := ":" + strconv.Itoa(port)
+
+	if protocol == "tcp" || protocol == ProtocolAll {
+		listener, err := lc.Listen(ctx, "tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = listener.Close()
+	}
+
+	if protocol == "udp" || protocol == ProtocolAll {
+		conn, err := lc.ListenPacket(ctx, "udp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+	}
+
+	return true
+}
+
+// localPortRange returns the kernel ephemeral port range.
+func localPortRange() PortRange {
+	data, err := os.ReadFile(localPortRangePath)
+	if err != nil {
+		return defaultLocalPortRange
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return defaultLocalPortRange
+	}
+
+	r, err := ParsePortRange(fields[0] + "-" + fields[1])
+	if err != nil {
+		return defaultLocalPortRange
+	}
+
+	return r
+}
+
+// localReservedPorts returns the set of ports reserved by the kernel.
+// The file holds a comma separated list of ports and ranges, e.g. "8080,9000-9010".
+func localReservedPorts() map[int]bool {
+	reserved := make(map[int]bool)
+
+	data, err := os.ReadFile(localReservedPortsPath)
+	if err != nil {
+		return reserved
+	}
+
+	for _, item := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		if item == "" {
+			continue
+		}
+
+		r, err := ParsePortRange(item)
+		if err != nil {
+			continue
+		}
+
+		for port := r.Low; port <= r.High; port++ {
+			reserved[port] = true
+		}
+	}
+
+	return reserved
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParsePortRange(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    PortRange
+		wantErr bool
+	}{
+		"range": {
+			input: "8000-8100",
+			want:  PortRange{Low: 8000, High: 8100},
+		},
+		"single port": {
+			input: "8080",
+			want:  PortRange{Low: 8080, High: 8080},
+		},
+		"spaces": {
+			input: " 8000 - 8100 ",
+			want:  PortRange{Low: 8000, High: 8100},
+		},
+		"whole range": {
+			input: "1-65535",
+			want:  PortRange{Low: 1, High: 65535},
+		},
+		"empty": {
+			input:   "",
+			wantErr: true,
+		},
+		"not a number": {
+			input:   "http",
+			wantErr: true,
+		},
+		"missing high": {
+			input:   "8000-",
+			wantErr: true,
+		},
+		"reversed": {
+			input:   "8100-8000",
+			wantErr: true,
+		},
+		"port zero": {
+			input:   "0-100",
+			wantErr: true,
+		},
+		"above 65535": {
+			input:   "65000-65536",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePortRange(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidPortRange) {
+					t.Fatalf("ParsePortRange(%q) error = %v, want %v", tc.input, err, ErrInvalidPortRange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePortRange(%q) error = %v", tc.input, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("ParsePortRange(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCandidatePorts(t *testing.T) {
+	tests := map[string]struct {
+		portRange PortRange
+		near      int
+		want      []int
+	}{
+		"ascending without a preferred port": {
+			portRange: PortRange{Low: 10, High: 14},
+			want:      []int{10, 11, 12, 13, 14},
+		},
+		"nearest first, above before below": {
+			portRange: PortRange{Low: 10, High: 14},
+			near:      12,
+			want:      []int{12, 13, 11, 14, 10},
+		},
+		"near the low end": {
+			portRange: PortRange{Low: 10, High: 14},
+			near:      10,
+			want:      []int{10, 11, 12, 13, 14},
+		},
+		"near the high end": {
+			portRange: PortRange{Low: 10, High: 14},
+			near:      14,
+			want:      []int{14, 13, 12, 11, 10},
+		},
+		"single port": {
+			portRange: PortRange{Low: 80, High: 80},
+			near:      80,
+			want:      []int{80},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := candidatePorts(tc.portRange, tc.near); !slices.Equal(got, tc.want) {
+				t.Errorf("candidatePorts(%v, %d) = %v, want %v", tc.portRange, tc.near, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOccupiedPorts(t *testing.T) {
+	processes := []Process{
+		{Port: 80, Protocol: ProtocolTCP},
+		{Port: 443, Protocol: ProtocolTCP6},
+		{Port: 53, Protocol: ProtocolUDP},
+	}
+
+	tests := map[string]struct {
+		protocol string
+		want     []int
+	}{
+		"tcp":  {protocol: "tcp", want: []int{80, 443}},
+		"udp":  {protocol: "udp", want: []int{53}},
+		"both": {protocol: ProtocolAll, want: []int{53, 80, 443}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			occupied := occupiedPorts(processes, tc.protocol)
+
+			got := make([]int, 0, len(occupied))
+			for port := range occupied {
+				got = append(got, port)
+			}
+			slices.Sort(got)
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("occupiedPorts(%q) = %v, want %v", tc.protocol, got, tc.want)
+			}
+		})
+	}
+}
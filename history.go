@@ -0,0 +1,203 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultHistory is how long the samples for the TUI history are kept.
+const defaultHistory = 10 * time.Minute
+
+// WithHistory returns an option that keeps the per-process samples of the given
+// duration and records when each port was first seen and by whom.
+func WithHistory(duration time.Duration) ManagerOption {
+	return func(m *ProcessManager) { m.history = &processHistory{duration: duration} }
+}
+
+// ProcessSample holds the state of a process at a refresh.
+type ProcessSample struct {
+	At         time.Time `json:"at"`
+	Sockets    int       `json:"sockets"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryRSS  uint64    `json:"memory_rss"`
+}
+
+// PortOwner is a process seen bound to a port.
+type PortOwner struct {
+	ProcessRef
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// PortHistory records the processes bound to a port within the history duration.
+type PortHistory struct {
+	Protocol  string      `json:"protocol"`
+	Port      int         `json:"port"`
+	FirstSeen time.Time   `json:"first_seen"`
+	Owners    []PortOwner `json:"owners"`
+}
+
+// historySnapshot holds the samples of all the processes at a refresh by PID.
+type historySnapshot struct {
+	at      time.Time
+	samples map[int]ProcessSample
+}
+
+// portKey identifies a port by its transport, "tcp" or "udp", and number.
+type portKey struct {
+	transport string
+	port      int
+}
+
+func newPortKey(protocol string, port int) portKey {
+	if strings.HasPrefix(strings.ToUpper(protocol), ProtocolTCP) {
+		return portKey{transport: "tcp", port: port}
+	}
+
+	return portKey{transport: "udp", port: port}
+}
+
+// processHistory keeps the recent snapshots and the owners of the ports.
+type processHistory struct {
+	duration  time.Duration
+	snapshots []historySnapshot
+	ports     map[portKey]*PortHistory
+}
+
+// record adds the snapshot of the processes taken at the time and forgets
+// the snapshots and port owners older than the history duration.
+func (h *processHistory) record(processes []Process, now time.Time) {
+	if h.ports == nil {
+		h.ports = make(map[portKey]*PortHistory)
+	}
+
+	snapshot := historySnapshot{at: now, samples: make(map[int]ProcessSample)}
+
+	for _, p := range processes {
+		sample := snapshot.samples[p.PID]
+		sample.At = now
+		sample.Sockets++
+		sample.CPUPercent, sample.MemoryRSS = p.CPUPercent, p.MemoryRSS
+		snapshot.samples[p.PID] = sample
+
+		if isListener(p) {
+			h.recordOwner(p, now)
+		}
+	}
+
+	h.snapshots = append(h.snapshots, snapshot)
+	h.prune(now.Add(-h.duration))
+}
+
+// prune forgets the snapshots taken before the time. Refreshes don't only come
+// from the ticker, a kill refreshes too, so the snapshots are not counted.
+func (h *processHistory) prune(before time.Time) {
+	old := 0
+	for old < len(h.snapshots) && h.snapshots[old].at.Before(before) {
+		old++
+	}
+	h.snapshots = slices.Delete(h.snapshots, 0, old)
+
+	h.prunePorts(before)
+}
+
+// prunePorts forgets the owners last seen before the time and the ports left without owners.
+func (h *processHistory) prunePorts(before time.Time) {
+	for key, port := range h.ports {
+		port.Owners = slices.DeleteFunc(port.Owners, func(owner PortOwner) bool {
+			return owner.LastSeen.Before(before)
+		})
+
+		if len(port.Owners) == 0 {
+			delete(h.ports, key)
+			continue
+		}
+
+		// The owners are kept in the order they were first seen.
+		port.FirstSeen = port.Owners[0].FirstSeen
+	}
+}
+
+// recordOwner marks the process as seen bound to its port.
+func (h *processHistory) recordOwner(p Process, now time.Time) {
+	key := newPortKey(p.Protocol, p.Port)
+
+	port, ok := h.ports[key]
+	if !ok {
+		port = &PortHistory{Protocol: key.transport, Port: p.Port, FirstSeen: now}
+		h.ports[key] = port
+	}
+
+	ref := ProcessRef{PID: p.PID, Name: p.Name}
+	for i := range port.Owners {
+		if port.Owners[i].ProcessRef == ref {
+			port.Owners[i].LastSeen = now
+			return
+		}
+	}
+
+	port.Owners = append(port.Owners, PortOwner{ProcessRef: ref, FirstSeen: now, LastSeen: now})
+}
+
+// ProcessHistories returns the recent samples of all the processes by PID,
+// the same as ProcessHistory for each of them.
+func (m *ProcessManager) ProcessHistories() map[int][]ProcessSample {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	histories := make(map[int][]ProcessSample)
+	if m.history == nil {
+		return histories
+	}
+
+	for _, snapshot := range m.history.snapshots {
+		for pid, sample := range snapshot.samples {
+			histories[pid] = append(histories[pid], sample)
+		}
+	}
+
+	return histories
+}
+
+// ProcessHistory returns the recent samples of the process, the oldest first.
+// Refreshes where the process had no sockets are skipped.
+// It is empty unless the history is enabled.
+func (m *ProcessManager) ProcessHistory(pid int) []ProcessSample {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.history == nil {
+		return nil
+	}
+
+	var samples []ProcessSample
+	for _, snapshot := range m.history.snapshots {
+		if sample, ok := snapshot.samples[pid]; ok {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
+
+// PortHistory returns the processes seen listening on the TCP or UDP port, the earliest first.
+// It reports false when the port was not seen or the history is disabled.
+func (m *ProcessManager) PortHistory(protocol string, port int) (PortHistory, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.history == nil || m.history.ports == nil {
+		return PortHistory{}, false
+	}
+
+	history, ok := m.history.ports[newPortKey(protocol, port)]
+	if !ok {
+		return PortHistory{}, false
+	}
+
+	result := *history
+	result.Owners = slices.Clone(history.Owners)
+
+	return result, true
+}
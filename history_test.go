@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProcessHistoryRecord(t *testing.T) {
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	nginx := []Process{
+		{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, CPUPercent: 1.5, MemoryRSS: 1024},
+		{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: "ESTABLISHED", RemoteAddr: "10.0.0.2:51000", CPUPercent: 1.5, MemoryRSS: 1024},
+	}
+	caddy := []Process{
+		{PID: 20, Name: "caddy", Port: 80, Protocol: ProtocolTCP, Status: StatusListen},
+	}
+
+	type refresh struct {
+		at        int
+		processes []Process
+	}
+
+	tests := map[string]struct {
+		refreshes     []refresh
+		wantSnapshots []time.Time
+		wantOwners    []PortOwner
+	}{
+		"samples by pid": {
+			refreshes:     []refresh{{at: 0, processes: nginx}},
+			wantSnapshots: []time.Time{at(0)},
+			wantOwners:    []PortOwner{{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, FirstSeen: at(0), LastSeen: at(0)}},
+		},
+		"extra refreshes kept within the duration": {
+			refreshes: []refresh{
+				{at: 0, processes: nginx}, {at: 1, processes: nginx}, {at: 2, processes: nginx},
+				{at: 3, processes: nginx}, {at: 4, processes: nginx}, {at: 10, processes: nginx},
+			},
+			wantSnapshots: []time.Time{at(0), at(1), at(2), at(3), at(4), at(10)},
+			wantOwners:    []PortOwner{{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, FirstSeen: at(0), LastSeen: at(10)}},
+		},
+		"old snapshots pruned": {
+			refreshes:     []refresh{{at: 0, processes: nginx}, {at: 5, processes: nginx}, {at: 12, processes: nginx}},
+			wantSnapshots: []time.Time{at(5), at(12)},
+			wantOwners:    []PortOwner{{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, FirstSeen: at(0), LastSeen: at(12)}},
+		},
+		"port taken over": {
+			refreshes:     []refresh{{at: 0, processes: nginx}, {at: 5, processes: caddy}},
+			wantSnapshots: []time.Time{at(0), at(5)},
+			wantOwners: []PortOwner{
+				{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, FirstSeen: at(0), LastSeen: at(0)},
+				{ProcessRef: ProcessRef{PID: 20, Name: "caddy"}, FirstSeen: at(5), LastSeen: at(5)},
+			},
+		},
+		"previous owner forgotten": {
+			refreshes:     []refresh{{at: 0, processes: nginx}, {at: 5, processes: caddy}, {at: 15, processes: caddy}},
+			wantSnapshots: []time.Time{at(5), at(15)},
+			wantOwners:    []PortOwner{{ProcessRef: ProcessRef{PID: 20, Name: "caddy"}, FirstSeen: at(5), LastSeen: at(15)}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			history := &processHistory{duration: 10 * time.Second}
+			for _, r := range tc.refreshes {
+				history.record(r.processes, at(r.at))
+			}
+
+			var snapshots []time.Time
+			for _, snapshot := range history.snapshots {
+				snapshots = append(snapshots, snapshot.at)
+			}
+			if !slices.Equal(snapshots, tc.wantSnapshots) {
+				t.Errorf("snapshots = %v, want %v", snapshots, tc.wantSnapshots)
+			}
+
+			port, ok := history.ports[newPortKey(ProtocolTCP, 80)]
+			if !ok {
+				t.Fatal("port 80 is not in the history")
+			}
+			if !reflect.DeepEqual(port.Owners, tc.wantOwners) {
+				t.Errorf("owners = %+v, want %+v", port.Owners, tc.wantOwners)
+			}
+			if !port.FirstSeen.Equal(tc.wantOwners[0].FirstSeen) {
+				t.Errorf("first seen = %v, want %v", port.FirstSeen, tc.wantOwners[0].FirstSeen)
+			}
+		})
+	}
+}
+
+func TestProcessHistorySamples(t *testing.T) {
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	history := &processHistory{duration: time.Minute}
+	history.record([]Process{
+		{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, CPUPercent: 1.5, MemoryRSS: 1024},
+		{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: "ESTABLISHED", CPUPercent: 1.5, MemoryRSS: 1024},
+		{PID: 20, Name: "dnsmasq", Port: 53, Protocol: ProtocolUDP, MemoryRSS: 512},
+	}, start)
+
+	want := map[int]ProcessSample{
+		10: {At: start, Sockets: 2, CPUPercent: 1.5, MemoryRSS: 1024},
+		20: {At: start, Sockets: 1, MemoryRSS: 512},
+	}
+	if got := history.snapshots[0].samples; !reflect.DeepEqual(got, want) {
+		t.Errorf("samples = %+v, want %+v", got, want)
+	}
+	if _, ok := history.ports[newPortKey(ProtocolUDP, 53)]; !ok {
+		t.Error("the UDP listener on port 53 is not in the history")
+	}
+}
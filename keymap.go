@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// fixedKeys are the keys of the table view which can't be bound to actions,
+// mapped to what they do.
+var fixedKeys = map[string]string{
+	"enter":  "details",
+	"esc":    "cancel",
+	"up":     "line up",
+	"down":   "line down",
+	"ctrl+u": "half page up",
+	"ctrl+d": "half page down",
+}
+
+// keyMap holds the configurable TUI key bindings.
+// Moving by line and the dialog keys are fixed.
+type keyMap struct {
+	Search      key.Binding
+	Presets     key.Binding
+	Columns     key.Binding
+	Summary     key.Binding
+	History     key.Binding
+	TCP         key.Binding
+	UDP         key.Binding
+	Listen      key.Binding
+	Established key.Binding
+	Exposed     key.Binding
+	Clear       key.Binding
+	Kill        key.Binding
+	Free        key.Binding
+	Save        key.Binding
+	Copy        key.Binding
+	ScrollLeft  key.Binding
+	ScrollRight key.Binding
+	Expand      key.Binding
+	PageUp      key.Binding
+	PageDown    key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	Quit        key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Search:      newKeyBinding("Search", "/"),
+		Presets:     newKeyBinding("Presets", "p"),
+		Columns:     newKeyBinding("Columns", "c"),
+		Summary:     newKeyBinding("Summary", "s"),
+		History:     newKeyBinding("History", "h"),
+		TCP:         newKeyBinding("TCP", "t"),
+		UDP:         newKeyBinding("UDP", "u"),
+		Listen:      newKeyBinding("LISTEN", "l"),
+		Established: newKeyBinding("EST", "e"),
+		Exposed:     newKeyBinding("Exposed", "o"),
+		Clear:       newKeyBinding("Clear", "x"),
+		Kill:        newKeyBinding("Kill", "k"),
+		Free:        newKeyBinding("Free", "f"),
+		Save:        newKeyBinding("Save", "ctrl+s"),
+		Copy:        newKeyBinding("Copy", "y"),
+		ScrollLeft:  newKeyBinding("Scroll", "shift+left"),
+		ScrollRight: newKeyBinding("Scroll", "shift+right"),
+		Expand:      newKeyBinding("Expand", "w"),
+		PageUp:      newKeyBinding("Page up", "pgup"),
+		PageDown:    newKeyBinding("Page down", "pgdown"),
+		Top:         newKeyBinding("Top", "home", "g"),
+		Bottom:      newKeyBinding("Bottom", "end", "G"),
+		Quit:        newKeyBinding("Quit", "q", "ctrl+c"),
+	}
+}
+
+// newKeyBinding creates a binding labelled with its first key.
+func newKeyBinding(description string, keys ...string) key.Binding {
+	binding := key.NewBinding(key.WithKeys(keys...))
+	if len(keys) == 0 {
+		binding.SetEnabled(false)
+		return binding
+	}
+
+	binding.SetHelp(keyLabel(keys[0]), description)
+
+	return binding
+}
+
+// actions maps the action names used in the config to the bindings.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"search":       &k.Search,
+		"presets":      &k.Presets,
+		"columns":      &k.Columns,
+		"summary":      &k.Summary,
+		"history":      &k.History,
+		"tcp":          &k.TCP,
+		"udp":          &k.UDP,
+		"listen":       &k.Listen,
+		"established":  &k.Established,
+		"exposed":      &k.Exposed,
+		"clear":        &k.Clear,
+		"kill":         &k.Kill,
+		"free":         &k.Free,
+		"save":         &k.Save,
+		"copy":         &k.Copy,
+		"scroll_left":  &k.ScrollLeft,
+		"scroll_right": &k.ScrollRight,
+		"expand":       &k.Expand,
+		"page_up":      &k.PageUp,
+		"page_down":    &k.PageDown,
+		"top":          &k.Top,
+		"bottom":       &k.Bottom,
+		"quit":         &k.Quit,
+	}
+}
+
+// apply rebinds the actions to the keys and reports unknown actions
+// and keys bound to more than one action.
+func (k *keyMap) apply(overrides map[string][]string) error {
+	actions := k.actions()
+
+	for name, keys := range overrides {
+		binding, ok := actions[name]
+		if !ok {
+			names := make([]string, 0, len(actions))
+			for action := range actions {
+				names = append(names, action)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("unknown action %q, available: %s", name, strings.Join(names, ", "))
+		}
+
+		*binding = newKeyBinding(binding.Help().Desc, keys...)
+	}
+
+	owners := make(map[string]string)
+	for name, binding := range actions {
+		for _, bound := range binding.Keys() {
+			if fixed, ok := fixedKeys[bound]; ok {
+				return fmt.Errorf("key %q of %s is fixed to %s", bound, name, fixed)
+			}
+			if owner, ok := owners[bound]; ok {
+				first, second := min(owner, name), max(owner, name)
+				return fmt.Errorf("key %q is bound to both %s and %s", bound, first, second)
+			}
+			owners[bound] = name
+		}
+	}
+
+	return nil
+}
+
+// tableKeyMap returns the navigation keys of the table: the configured pages
+// and ends, and the fixed lines and half pages.
+func (k keyMap) tableKeyMap() table.KeyMap {
+	return table.KeyMap{
+		LineUp:       key.NewBinding(key.WithKeys("up")),
+		LineDown:     key.NewBinding(key.WithKeys("down")),
+		PageUp:       k.PageUp,
+		PageDown:     k.PageDown,
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		GotoTop:      k.Top,
+		GotoBottom:   k.Bottom,
+	}
+}
+
+// shortcut renders the binding as "[key] Description" for the header,
+// disabled bindings render as an empty string.
+func shortcut(binding key.Binding) string {
+	if !binding.Enabled() {
+		return ""
+	}
+
+	help := binding.Help()
+
+	return fmt.Sprintf("[%s] %s", help.Key, help.Desc)
+}
+
+// joinShortcuts renders the enabled bindings separated by sep.
+func joinShortcuts(sep string, bindings ...key.Binding) string {
+	shortcuts := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		if s := shortcut(binding); s != "" {
+			shortcuts = append(shortcuts, s)
+		}
+	}
+
+	return strings.Join(shortcuts, sep)
+}
+
+// scrollShortcut renders a pair of scroll bindings as one shortcut, e.g. "[Shift+←/→] Scroll".
+func scrollShortcut(left, right key.Binding) string {
+	switch {
+	case !left.Enabled():
+		return shortcut(right)
+	case !right.Enabled():
+		return shortcut(left)
+	}
+
+	leftKey, rightKey := left.Help().Key, right.Help().Key
+	if i := strings.LastIndex(leftKey, "+"); i > 0 {
+		rightKey = strings.TrimPrefix(rightKey, leftKey[:i+1])
+	}
+
+	return fmt.Sprintf("[%s/%s] %s", leftKey, rightKey, left.Help().Desc)
+}
+
+// keyLabel formats the key for display, e.g. "ctrl+s" as "Ctrl+S" and "shift+left" as "Shift+←".
+func keyLabel(k string) string {
+	parts := strings.Split(k, "+")
+
+	for i, part := range parts {
+		switch part {
+		case "left":
+			parts[i] = "←"
+		case "right":
+			parts[i] = "→"
+		case "up":
+			parts[i] = "↑"
+		case "down":
+			parts[i] = "↓"
+		default:
+			if len(parts) > 1 && part != "" {
+				parts[i] = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+	}
+
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeyMapApply(t *testing.T) {
+	tests := map[string]struct {
+		overrides map[string][]string
+		wantKill  []string
+		wantQuit  []string
+		wantErr   string
+	}{
+		"defaults": {
+			wantKill: []string{"k"},
+			wantQuit: []string{"q", "ctrl+c"},
+		},
+		"rebound": {
+			overrides: map[string][]string{"kill": {"K", "delete"}},
+			wantKill:  []string{"K", "delete"},
+			wantQuit:  []string{"q", "ctrl+c"},
+		},
+		"swapped": {
+			overrides: map[string][]string{"kill": {"q"}, "quit": {"k"}},
+			wantKill:  []string{"q"},
+			wantQuit:  []string{"k"},
+		},
+		"disabled": {
+			overrides: map[string][]string{"kill": {}},
+			wantQuit:  []string{"q", "ctrl+c"},
+		},
+		"unknown action": {
+			overrides: map[string][]string{"explode": {"x"}},
+			wantErr:   `unknown action "explode"`,
+		},
+		"bound twice": {
+			overrides: map[string][]string{"kill": {"q"}},
+			wantErr:   `key "q" is bound to both kill and quit`,
+		},
+		"bound to a default key": {
+			overrides: map[string][]string{"search": {"t"}},
+			wantErr:   `key "t" is bound to both search and tcp`,
+		},
+		"fixed key": {
+			overrides: map[string][]string{"search": {"enter"}},
+			wantErr:   `key "enter" of search is fixed to details`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := defaultKeyMap()
+
+			err := keys.apply(tc.overrides)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("apply() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+
+			if got := keys.Kill.Keys(); !slices.Equal(got, tc.wantKill) {
+				t.Errorf("kill keys = %v, want %v", got, tc.wantKill)
+			}
+			if keys.Kill.Enabled() != (len(tc.wantKill) > 0) {
+				t.Errorf("kill enabled = %t, want %t", keys.Kill.Enabled(), len(tc.wantKill) > 0)
+			}
+			if got := keys.Quit.Keys(); !slices.Equal(got, tc.wantQuit) {
+				t.Errorf("quit keys = %v, want %v", got, tc.wantQuit)
+			}
+		})
+	}
+}
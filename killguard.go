@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const (
+	// ErrKillNotConfirmed indicates that killing the process needs a second confirmation.
+	ErrKillNotConfirmed = Error("kill needs a second confirmation")
+)
+
+// defaultProtectedProcesses are the processes which need a second confirmation
+// to be killed when the config doesn't list any.
+var defaultProtectedProcesses = []string{
+	"sshd", "systemd", "init", "launchd", "dockerd", "containerd",
+}
+
+// KillProtection configures which processes KillProcess refuses to kill or kills
+// only after a second confirmation. PID 1, portman itself and kernel threads are always refused.
+type KillProtection struct {
+	// Processes are the names of the protected processes. The parents of portman,
+	// such as the shell and the terminal it runs in, are protected as well.
+	Processes []string
+	// Refuse refuses to kill the protected processes instead of asking again.
+	Refuse bool
+}
+
+// WithKillProtection returns an option that sets the processes protected from KillProcess.
+func WithKillProtection(protection KillProtection) ManagerOption {
+	return func(m *ProcessManager) { m.protection = protection }
+}
+
+// KillRisk explains why killing a process is dangerous.
+type KillRisk struct {
+	Reason string
+	// Refused means the process can't be killed, otherwise it can after a second confirmation.
+	Refused bool
+}
+
+// KillRisk returns why killing the process is dangerous, or false if it is not.
+func (m *ProcessManager) KillRisk(ctx context.Context, pid int) (KillRisk, bool) {
+	switch {
+	case pid <= 1:
+		return KillRisk{Reason: fmt.Sprintf("PID %d is the init process", pid), Refused: true}, true
+	case pid == os.Getpid():
+		return KillRisk{Reason: fmt.Sprintf("PID %d is portman itself", pid), Refused: true}, true
+	}
+
+	details, err := m.ProcessDetails(ctx, pid, false)
+	if err != nil {
+		// The process is gone, killing it fails on its own.
+		return KillRisk{}, false
+	}
+
+	if isKernelThread(details) {
+		return KillRisk{Reason: fmt.Sprintf("PID %d is a kernel thread", pid), Refused: true}, true
+	}
+
+	if self, err := process.NewProcessWithContext(ctx, int32(os.Getpid())); err == nil {
+		for _, parent := range parentChain(ctx, self) {
+			if parent.PID == pid {
+				reason := fmt.Sprintf("%s (%d) is a parent of portman, such as your shell or terminal", displayName(parent.Name), pid)
+				return KillRisk{Reason: reason, Refused: m.protection.Refuse}, true
+			}
+		}
+	}
+
+	if slices.Contains(m.protection.Processes, details.Name) {
+		return KillRisk{Reason: details.Name + " is a protected process", Refused: m.protection.Refuse}, true
+	}
+
+	return KillRisk{}, false
+}
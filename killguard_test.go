@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestKillRisk(t *testing.T) {
+	victim := startSleep(t)
+
+	tests := map[string]struct {
+		pid         int
+		protection  KillProtection
+		wantRisk    bool
+		wantRefused bool
+	}{
+		"init": {
+			pid:         1,
+			wantRisk:    true,
+			wantRefused: true,
+		},
+		"invalid pid": {
+			pid:         0,
+			wantRisk:    true,
+			wantRefused: true,
+		},
+		"portman itself": {
+			pid:         os.Getpid(),
+			wantRisk:    true,
+			wantRefused: true,
+		},
+		"parent of portman": {
+			pid:      os.Getppid(),
+			wantRisk: true,
+		},
+		"parent of portman with refuse": {
+			pid:         os.Getppid(),
+			protection:  KillProtection{Refuse: true},
+			wantRisk:    true,
+			wantRefused: true,
+		},
+		"protected name": {
+			pid:        victim,
+			protection: KillProtection{Processes: []string{"sshd", "sleep"}},
+			wantRisk:   true,
+		},
+		"protected name with refuse": {
+			pid:         victim,
+			protection:  KillProtection{Processes: []string{"sleep"}, Refuse: true},
+			wantRisk:    true,
+			wantRefused: true,
+		},
+		"unprotected": {
+			pid:        victim,
+			protection: KillProtection{Processes: defaultProtectedProcesses},
+		},
+		"unprotected with refuse": {
+			pid:        victim,
+			protection: KillProtection{Processes: defaultProtectedProcesses, Refuse: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.pid == 1 && os.Getppid() == 1 {
+				t.Skip("portman is a child of PID 1")
+			}
+
+			pm := &ProcessManager{protection: tc.protection}
+
+			risk, ok := pm.KillRisk(context.Background(), tc.pid)
+			if ok != tc.wantRisk {
+				t.Fatalf("KillRisk() = %+v, %t, want risk %t", risk, ok, tc.wantRisk)
+			}
+			if risk.Refused != tc.wantRefused {
+				t.Errorf("KillRisk() refused = %t, want %t", risk.Refused, tc.wantRefused)
+			}
+			if ok && risk.Reason == "" {
+				t.Error("KillRisk() reason is empty")
+			}
+		})
+	}
+}
+
+func TestKillProcessNeedsConfirmation(t *testing.T) {
+	victim := startSleep(t)
+
+	pm := &ProcessManager{protection: KillProtection{Processes: []string{"sleep"}}}
+
+	if err := pm.KillProcess(context.Background(), victim, false); !errors.Is(err, ErrKillNotConfirmed) {
+		t.Fatalf("KillProcess() error = %v, want %v", err, ErrKillNotConfirmed)
+	}
+
+	pm.protection.Refuse = true
+	if err := pm.KillProcess(context.Background(), victim, true); !errors.Is(err, ErrKillRefused) {
+		t.Fatalf("KillProcess() error = %v, want %v", err, ErrKillRefused)
+	}
+
+	if err := pm.KillProcess(context.Background(), os.Getpid(), true); !errors.Is(err, ErrKillRefused) {
+		t.Fatalf("KillProcess() of itself error = %v, want %v", err, ErrKillRefused)
+	}
+}
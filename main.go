@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -18,12 +19,22 @@ const (
 	version  = "0.1.0"
 )
 
+// Exit codes of the commands. Policy violations are told apart from the failures,
+// so CI can tell a broken check from a failed one.
+const (
+	exitViolation = 1
+	exitError     = 2
+)
+
 func main() {
 	var (
 		filterPort     uint
 		filterProcess  string
 		showListenOnly bool
 		hideBorders    bool
+		fromFile       string
+		preset         string
+		color          = colorAuto
 	)
 
 	cmd := scotty.Command{
@@ -35,18 +46,60 @@ func main() {
 			flags.StringVar(&filterProcess, "process", "", "Filter by process name (case-insensitive partial match)")
 			flags.BoolVar(&showListenOnly, "listen", false, "Show only listening ports")
 			flags.BoolVar(&hideBorders, "no-borders", false, "Hide table borders for cleaner output")
+			flags.StringVar(&fromFile, "from-file", "", "Open a saved snapshot read-only instead of live data")
+			flags.StringVar(&preset, "preset", "", "Start with a saved search preset applied")
+			flags.Var(&color, "color", "Colorize the output: auto, always or never")
+			flags.StringVarE(&configPath, "config", "PORTMAN_CONFIG", "", "Path to the config file (default $XDG_CONFIG_HOME/portman/config.yaml)")
 		},
 
 		Run: func(cmd *scotty.Command, args []string) error {
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
-			processManager, err := NewProcessManager(ctx)
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			store, err := LoadSearchStore()
+			if err != nil {
+				return fmt.Errorf("load search store: %w", err)
+			}
+
+			var processManager *ProcessManager
+
+			if fromFile != "" {
+				snapshot, err := LoadSnapshot(fromFile)
+				if err != nil {
+					return err
+				}
+				processManager = NewProcessManagerFromSnapshot(snapshot)
+			} else {
+				var err error
+				processManager, err = newProcessManagerWithConfig(ctx, config,
+					WithBandwidthSampling(),
+					WithHistory(config.history()),
+				)
+				if err != nil {
+					return fmt.Errorf("new process manager: %w", err)
+				}
+			}
+			defer processManager.Stop()
+
+			m, err := newTableModel(processManager, store, config)
 			if err != nil {
-				return fmt.Errorf("new process manager: %w", err)
+				return fmt.Errorf("invalid config: %w", err)
 			}
 
-			m := newTableModel(processManager)
+			if preset != "" {
+				p, ok := store.Preset(preset)
+				if !ok {
+					return fmt.Errorf("unknown preset: %s", preset)
+				}
+				if err := m.applyPreset(p); err != nil {
+					return fmt.Errorf("apply preset %s: %w", preset, err)
+				}
+			}
 
 			p := tea.NewProgram(m,
 				tea.WithOutput(os.Stdout),
@@ -63,8 +116,92 @@ func main() {
 		},
 	}
 
+	cmd.AddSubcommands(
+		newFreeCommand(),
+		newCheckCommand(),
+		newSaveCommand(),
+		newDiffCommand(),
+		newServeCommand(),
+	)
+
 	if err := cmd.Exec(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, ErrPolicyViolation) {
+			os.Exit(exitViolation)
+		}
+		os.Exit(exitError)
+	}
+}
+
+// setConfigFlag registers the -config flag of a subcommand. The subcommand flags
+// are set after the root ones are parsed, so it defaults to the root -config value.
+func setConfigFlag(flags *scotty.FlagSet) {
+	flags.StringVar(&configPath, "config", configPath, "Path to the config file (default $XDG_CONFIG_HOME/portman/config.yaml)")
+}
+
+// setColorFlag registers the -color flag of a subcommand. Setting it switches
+// the color profile, so the root -color value stays unless it is given again.
+func setColorFlag(flags *scotty.FlagSet) {
+	color := colorAuto
+	flags.Var(&color, "color", "Colorize the output: auto, always or never")
+}
+
+// loadConfig loads the user config and applies its theme.
+func loadConfig() (Config, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return config, fmt.Errorf("load config: %w", err)
+	}
+
+	theme, err := config.theme()
+	if err != nil {
+		return config, fmt.Errorf("load config: %w", err)
+	}
+	applyTheme(theme)
+
+	return config, nil
+}
+
+// newConfiguredProcessManager creates a ProcessManager set up according to the user config.
+func newConfiguredProcessManager(ctx context.Context) (*ProcessManager, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return newProcessManagerWithConfig(ctx, config)
+}
+
+// newListedProcessManager creates a ProcessManager set up according to the user config
+// and fails if it couldn't list the sockets. Commands reporting on the list would
+// otherwise take a failed fetch for a host without sockets.
+func newListedProcessManager(ctx context.Context) (*ProcessManager, error) {
+	processManager, err := newConfiguredProcessManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := processManager.Err(); err != nil && !errors.Is(err, ErrNoConnectionsFound) {
+		processManager.Stop()
+		return nil, err
+	}
+
+	return processManager, nil
+}
+
+// newProcessManagerWithConfig creates a ProcessManager set up according to the loaded config.
+// The options are applied after the configured ones.
+func newProcessManagerWithConfig(ctx context.Context, config Config, options ...ManagerOption) (*ProcessManager, error) {
+	services, err := NewServiceRegistry(config.Services)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
 	}
+
+	options = append([]ManagerOption{
+		WithServiceRegistry(services),
+		WithRefreshInterval(config.refreshInterval()),
+		WithKillProtection(config.killProtection()),
+	}, options...)
+
+	return NewProcessManager(ctx, options...)
 }
@@ -6,9 +6,34 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	md "github.com/nao1215/markdown"
 )
 
+// processTableRow returns the cells of the process in the markdown and plain text tables.
+// The Status and Recv-Q of a listener with a full backlog are passed through highlight.
+func processTableRow(process Process, highlight func(string) string) []string {
+	status, recvQ := process.Status, columnValue("recvq", process)
+	if process.BacklogFull() {
+		status, recvQ = highlight(status), highlight(recvQ)
+	}
+
+	return []string{
+		strconv.Itoa(process.PID),
+		process.Name,
+		strconv.Itoa(process.Port),
+		process.Service,
+		process.Protocol,
+		status,
+		process.LocalAddr,
+		process.RemoteAddr,
+		recvQ,
+		columnValue("sendq", process),
+		columnValue("retrans", process),
+		columnValue("rtt", process),
+	}
+}
+
 func RenderMarkdownTableForProcesses(processes []Process, hideBorders bool) (string, error) {
 	if hideBorders {
 		return renderPlainTextTable(processes), nil
@@ -17,19 +42,15 @@ func RenderMarkdownTableForProcesses(processes []Process, hideBorders bool) (str
 	var byf bytes.Buffer
 	doc := md.NewMarkdown(&byf)
 	table := md.TableSet{
-		Header: []string{"PID", "Process", "Port", "Protocol", "Status", "Local Address", "Remote Address"},
-		Rows:   make([][]string, 0, len(processes)),
+		Header: []string{
+			"PID", "Process", "Port", "Service", "Protocol", "Status", "Local Address", "Remote Address",
+			"Recv-Q", "Send-Q", "Retrans", "RTT",
+		},
+		Rows: make([][]string, 0, len(processes)),
 	}
 
 	for _, process := range processes {
-		table.Rows = append(table.Rows, []string{
-			strconv.Itoa(process.PID),
-			process.Name,
-			strconv.Itoa(process.Port),
-			process.Protocol,
-			process.Status,
-			process.LocalAddr,
-		})
+		table.Rows = append(table.Rows, processTableRow(process, func(s string) string { return "**" + s + "**" }))
 	}
 
 	if err := doc.Table(table).Build(); err != nil {
@@ -45,37 +66,33 @@ func renderPlainTextTable(processes []Process) string {
 		return "No processes found.\n"
 	}
 
-	headers := []string{"PID", "PROCESS", "PORT", "PROTOCOL", "STATUS", "LOCAL ADDRESS", "REMOTE ADDRESS"}
+	headers := []string{
+		"PID", "PROCESS", "PORT", "SERVICE", "PROTOCOL", "STATUS", "LOCAL ADDRESS", "REMOTE ADDRESS",
+		"RECV-Q", "SEND-Q", "RETRANS", "RTT",
+	}
 
 	// Collect all data including headers
 	var allRows [][]string
 	allRows = append(allRows, headers)
 
 	for _, process := range processes {
-		allRows = append(allRows, []string{
-			strconv.Itoa(process.PID),
-			process.Name,
-			strconv.Itoa(process.Port),
-			process.Protocol,
-			process.Status,
-			process.LocalAddr,
-		})
+		allRows = append(allRows, processTableRow(process, func(s string) string { return failureStyle.Render(s) }))
 	}
 
 	// Calculate column widths
 	colWidths := make([]int, len(headers))
 	for _, row := range allRows {
 		for i, cell := range row {
-			if len(cell) > colWidths[i] {
-				colWidths[i] = len(cell)
+			if width := lipgloss.Width(cell); width > colWidths[i] {
+				colWidths[i] = width
 			}
 		}
 	}
 
-	// Set a maximum width for the Status column (index 4)
+	// Set a maximum width for the Status column (index 5)
 	// The longest status value is "LISTEN" or "ACTIVE" or "CLOSED" (6 chars)
-	if len(colWidths) > 4 && colWidths[4] > 6 {
-		colWidths[4] = 6
+	if len(colWidths) > 5 && colWidths[5] > 6 {
+		colWidths[5] = 6
 	}
 
 	var result strings.Builder
@@ -83,8 +100,8 @@ func renderPlainTextTable(processes []Process) string {
 	// Render each row with proper spacing
 	for i, row := range allRows {
 		for j, cell := range row {
-			// Left-align all columns except add padding
-			result.WriteString(fmt.Sprintf("%-*s", colWidths[j], cell))
+			// Left-align all columns except add padding, highlighted cells carry colors.
+			result.WriteString(cell + strings.Repeat(" ", max(colWidths[j]-lipgloss.Width(cell), 0)))
 			if j < len(row)-1 {
 				result.WriteString("   ") // 3 spaces between columns
 			}
@@ -105,3 +122,45 @@ func renderPlainTextTable(processes []Process) string {
 
 	return result.String()
 }
+
+// RenderMarkdownDiff renders the snapshot diff as a markdown table.
+func RenderMarkdownDiff(diff SnapshotDiff) (string, error) {
+	var byf bytes.Buffer
+	doc := md.NewMarkdown(&byf)
+	doc.H2f("Listener changes: %s → %s", describeSnapshot(diff.Before), describeSnapshot(diff.After))
+
+	if len(diff.Changes) == 0 {
+		doc.PlainText("No listener changes.")
+	} else {
+		table := md.TableSet{
+			Header: []string{"Change", "Protocol", "Port", "Before", "After"},
+			Rows:   make([][]string, 0, len(diff.Changes)),
+		}
+
+		for _, change := range diff.Changes {
+			var before, after string
+			if change.Before != nil {
+				before = change.Before.String()
+			}
+			if change.After != nil {
+				after = change.After.String()
+			}
+
+			table.Rows = append(table.Rows, []string{
+				change.Kind,
+				change.Protocol,
+				strconv.Itoa(change.Port),
+				before,
+				after,
+			})
+		}
+
+		doc.Table(table)
+	}
+
+	if err := doc.Build(); err != nil {
+		return "", fmt.Errorf("build diff: %w", err)
+	}
+
+	return byf.String(), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// metricsContentType is the content type of the Prometheus text format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// socketGroup groups the sockets counted by the portman_sockets metric.
+type socketGroup struct {
+	process  string
+	protocol string
+	state    string
+}
+
+// metricsHandler serves the current sockets and refresh counters of the manager
+// in the Prometheus text format.
+func metricsHandler(pm *ProcessManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		processes, err := pm.Processes(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", metricsContentType)
+		_ = WriteMetrics(w, processes, pm.RefreshStats())
+	})
+}
+
+// WriteMetrics writes the socket counts, the listening ports and the refresh counters
+// in the Prometheus text format.
+func WriteMetrics(w io.Writer, processes []Process, stats RefreshStats) error {
+	var b strings.Builder
+
+	sockets := make(map[socketGroup]int)
+	listeners := make(map[string]struct{})
+
+	for _, p := range processes {
+		protocol := strings.ToLower(p.Protocol)
+		state := p.Status
+		if state == "" {
+			state = StatusActive
+		}
+		sockets[socketGroup{process: p.Name, protocol: protocol, state: state}]++
+
+		if isListener(p) {
+			listeners[metricLabels(
+				"process", p.Name,
+				"pid", strconv.Itoa(p.PID),
+				"user", p.User,
+				"protocol", protocol,
+				"address", p.LocalAddr,
+				"port", strconv.Itoa(p.Port),
+				"service", p.Service,
+				"exposure", p.Exposure,
+			)] = struct{}{}
+		}
+	}
+
+	groups := make([]socketGroup, 0, len(sockets))
+	for group := range sockets {
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b socketGroup) int {
+		return cmp.Or(cmp.Compare(a.process, b.process), cmp.Compare(a.protocol, b.protocol), cmp.Compare(a.state, b.state))
+	})
+
+	writeMetricHeader(&b, "portman_sockets", "gauge", "Number of sockets by process, protocol and state.")
+	for _, group := range groups {
+		fmt.Fprintf(&b, "portman_sockets%s %d\n",
+			metricLabels("process", group.process, "protocol", group.protocol, "state", group.state), sockets[group])
+	}
+
+	writeMetricHeader(&b, "portman_listening_port_info", "gauge", "Listening sockets, always 1.")
+	for _, labels := range slices.Sorted(maps.Keys(listeners)) {
+		fmt.Fprintf(&b, "portman_listening_port_info%s 1\n", labels)
+	}
+
+	writeMetricHeader(&b, "portman_refresh_duration_seconds", "summary", "Time spent refreshing the socket list.")
+	fmt.Fprintf(&b, "portman_refresh_duration_seconds_sum %g\n", stats.Duration.Seconds())
+	fmt.Fprintf(&b, "portman_refresh_duration_seconds_count %d\n", stats.Count)
+
+	writeMetricHeader(&b, "portman_refresh_errors_total", "counter", "Number of failed refreshes of the socket list.")
+	fmt.Fprintf(&b, "portman_refresh_errors_total %d\n", stats.Errors)
+
+	writeMetricHeader(&b, "portman_last_refresh_timestamp_seconds", "gauge", "Unix time of the last successful refresh.")
+	lastSuccess := 0.0
+	if !stats.LastSuccess.IsZero() {
+		lastSuccess = float64(stats.LastSuccess.UnixMilli()) / 1000
+	}
+	fmt.Fprintf(&b, "portman_last_refresh_timestamp_seconds %g\n", lastSuccess)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of the metric.
+func writeMetricHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// metricLabels formats the name and value pairs as a label set, such as {a="1",b="2"}.
+func metricLabels(pairs ...string) string {
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, pairs[i]+`="`+escapeLabelValue(pairs[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// labelValueReplacer escapes the characters not allowed in label values.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	processes := []Process{
+		{PID: 10, Name: "nginx", User: "www", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:80", Service: "http", Exposure: ExposureWildcard},
+		{PID: 10, Name: "nginx", User: "www", Port: 80, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "10.0.0.1:80", RemoteAddr: "10.0.0.2:50000"},
+		{PID: 10, Name: "nginx", User: "www", Port: 80, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "10.0.0.1:80", RemoteAddr: "10.0.0.3:50000"},
+		{PID: 20, Name: "dns\"d", Port: 53, Protocol: ProtocolUDP, LocalAddr: "127.0.0.1:53", Exposure: ExposureLoopback},
+		{PID: 30, Name: "ntpd", Port: 40000, Protocol: ProtocolUDP, Status: StatusActive, LocalAddr: "10.0.0.1:40000", RemoteAddr: "10.0.0.4:123"},
+	}
+
+	stats := RefreshStats{
+		Count:       4,
+		Errors:      1,
+		Duration:    1500 * time.Millisecond,
+		LastSuccess: time.UnixMilli(1700000000250),
+	}
+
+	var b strings.Builder
+	if err := WriteMetrics(&b, processes, stats); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+
+	want := `# HELP portman_sockets Number of sockets by process, protocol and state.
+# TYPE portman_sockets gauge
+portman_sockets{process="dns\"d",protocol="udp",state="ACTIVE"} 1
+portman_sockets{process="nginx",protocol="tcp",state="ESTABLISHED"} 2
+portman_sockets{process="nginx",protocol="tcp",state="LISTEN"} 1
+portman_sockets{process="ntpd",protocol="udp",state="ACTIVE"} 1
+# HELP portman_listening_port_info Listening sockets, always 1.
+# TYPE portman_listening_port_info gauge
+portman_listening_port_info{process="dns\"d",pid="20",user="",protocol="udp",address="127.0.0.1:53",port="53",service="",exposure="loopback"} 1
+portman_listening_port_info{process="nginx",pid="10",user="www",protocol="tcp",address="0.0.0.0:80",port="80",service="http",exposure="wildcard"} 1
+# HELP portman_refresh_duration_seconds Time spent refreshing the socket list.
+# TYPE portman_refresh_duration_seconds summary
+portman_refresh_duration_seconds_sum 1.5
+portman_refresh_duration_seconds_count 4
+# HELP portman_refresh_errors_total Number of failed refreshes of the socket list.
+# TYPE portman_refresh_errors_total counter
+portman_refresh_errors_total 1
+# HELP portman_last_refresh_timestamp_seconds Unix time of the last successful refresh.
+# TYPE portman_last_refresh_timestamp_seconds gauge
+portman_last_refresh_timestamp_seconds 1.70000000025e+09
+`
+
+	if got := b.String(); got != want {
+		t.Errorf("WriteMetrics() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteMetricsNeverRefreshed(t *testing.T) {
+	var b strings.Builder
+	if err := WriteMetrics(&b, nil, RefreshStats{}); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+
+	if !strings.Contains(b.String(), "\nportman_last_refresh_timestamp_seconds 0\n") {
+		t.Errorf("WriteMetrics() = %s, want a zero last refresh timestamp", b.String())
+	}
+}
+
+func TestMetricLabels(t *testing.T) {
+	got := metricLabels("a", `back\slash`, "b", "quote\"", "c", "new\nline")
+	want := `{a="back\\slash",b="quote\"",c="new\nline"}`
+
+	if got != want {
+		t.Errorf("metricLabels() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// columnsChosenMsg is sent when the column chooser is confirmed.
+type columnsChosenMsg struct{ columns []string }
+
+// columnsClosedMsg is sent when the column chooser is dismissed without changes.
+type columnsClosedMsg struct{}
+
+// columnChooserModel lets the user show, hide and reorder the table columns.
+type columnChooserModel struct {
+	// columns holds all the columns, the visible ones first in their display order.
+	columns []string
+	visible map[string]bool
+	cursor  int
+	err     string
+	// height limits the lines of the box, the columns scroll with the cursor
+	// from offset when they don't fit. Zero shows all the columns.
+	height int
+	offset int
+}
+
+func newColumnChooserModel(visible []string) *columnChooserModel {
+	m := &columnChooserModel{
+		columns: slices.Clone(visible),
+		visible: make(map[string]bool, len(columnOrder)),
+	}
+
+	for _, name := range visible {
+		m.visible[name] = true
+	}
+
+	for _, name := range columnOrder {
+		if !m.visible[name] {
+			m.columns = append(m.columns, name)
+		}
+	}
+
+	return m
+}
+
+func (m *columnChooserModel) Init() tea.Cmd { return nil }
+
+func (m *columnChooserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	m.err = ""
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.columns)-1 {
+			m.cursor++
+		}
+	case "shift+up", "K":
+		if m.cursor > 0 {
+			m.columns[m.cursor-1], m.columns[m.cursor] = m.columns[m.cursor], m.columns[m.cursor-1]
+			m.cursor--
+		}
+	case "shift+down", "J":
+		if m.cursor < len(m.columns)-1 {
+			m.columns[m.cursor+1], m.columns[m.cursor] = m.columns[m.cursor], m.columns[m.cursor+1]
+			m.cursor++
+		}
+	case " ", "space":
+		name := m.columns[m.cursor]
+		m.visible[name] = !m.visible[name]
+	case "enter":
+		columns := m.Visible()
+		if len(columns) == 0 {
+			m.err = "At least one column has to be visible"
+			return m, nil
+		}
+		return m, func() tea.Msg { return columnsChosenMsg{columns: columns} }
+	case "esc", "q":
+		return m, func() tea.Msg { return columnsClosedMsg{} }
+	}
+
+	return m, nil
+}
+
+// SetHeight limits the box to the height.
+func (m *columnChooserModel) SetHeight(height int) {
+	m.height = height
+}
+
+func (m *columnChooserModel) View() string {
+	hints := []string{
+		presetHintStyle.Render("[Space] Show/hide  [Shift+↑/↓] Move"),
+		presetHintStyle.Render("[Enter] Apply and save  [Esc] Cancel"),
+	}
+	if m.err != "" {
+		hints = append([]string{searchErrorStyle.Render(m.err)}, hints...)
+	}
+
+	// The title, the hints and the blank lines after the title and the list
+	// are always shown, the columns get the lines left.
+	rows := len(m.columns)
+	if m.height > 0 {
+		rows = min(rows, max(m.height-confirmBoxStyle.GetVerticalFrameSize()-len(hints)-3, 1))
+	}
+	m.offset = min(max(m.offset, m.cursor-rows+1), m.cursor, len(m.columns)-rows)
+
+	title := "Columns"
+	if rows < len(m.columns) {
+		title = fmt.Sprintf("Columns %d-%d of %d", m.offset+1, m.offset+rows, len(m.columns))
+	}
+	lines := []string{title, ""}
+
+	for i, name := range m.columns[m.offset : m.offset+rows] {
+		i += m.offset
+
+		mark := "[ ]"
+		if m.visible[name] {
+			mark = "[x]"
+		}
+
+		line := "  " + mark + " " + tableColumns[name].title
+		if i == m.cursor {
+			line = presetSelectedStyle.Render("> " + mark + " " + tableColumns[name].title)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, hints...)
+
+	return confirmBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// Visible returns the visible columns in display order.
+func (m *columnChooserModel) Visible() []string {
+	columns := make([]string, 0, len(m.columns))
+	for _, name := range m.columns {
+		if m.visible[name] {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// dataTableModel is a table widget modeled after bubbles/table.
+// Unlike bubbles/table it measures and truncates cells by display width
+// ignoring ANSI escape sequences, so cells can carry their own colors.
+type dataTableModel struct {
+	KeyMap table.KeyMap
+
+	cols   []table.Column
+	rows   []table.Row
+	cursor int
+	start  int
+	height int
+	focus  bool
+	styles table.Styles
+}
+
+func newDataTableModel(cols []table.Column, height int) dataTableModel {
+	return dataTableModel{
+		KeyMap: table.DefaultKeyMap(),
+		cols:   cols,
+		height: height,
+		focus:  true,
+		styles: table.DefaultStyles(),
+	}
+}
+
+// Update handles the navigation keys.
+func (m dataTableModel) Update(msg tea.Msg) (dataTableModel, tea.Cmd) {
+	if !m.focus {
+		return m, nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.KeyMap.LineUp):
+			m.MoveUp(1)
+		case key.Matches(msg, m.KeyMap.LineDown):
+			m.MoveDown(1)
+		case key.Matches(msg, m.KeyMap.PageUp):
+			m.MoveUp(m.Height())
+		case key.Matches(msg, m.KeyMap.PageDown):
+			m.MoveDown(m.Height())
+		case key.Matches(msg, m.KeyMap.HalfPageUp):
+			m.MoveUp(m.Height() / 2)
+		case key.Matches(msg, m.KeyMap.HalfPageDown):
+			m.MoveDown(m.Height() / 2)
+		case key.Matches(msg, m.KeyMap.GotoTop):
+			m.GotoTop()
+		case key.Matches(msg, m.KeyMap.GotoBottom):
+			m.GotoBottom()
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the header and the visible rows padded to the table height.
+func (m dataTableModel) View() string {
+	lines := make([]string, 0, m.Height()+1)
+	lines = append(lines, m.headersView())
+
+	end := min(m.start+m.Height(), len(m.rows))
+	for i := m.start; i < end; i++ {
+		lines = append(lines, m.renderRow(i))
+	}
+
+	for len(lines) < m.Height()+1 {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m dataTableModel) headersView() string {
+	cells := make([]string, 0, len(m.cols))
+	for _, col := range m.cols {
+		if col.Width <= 0 {
+			continue
+		}
+		cells = append(cells, m.styles.Header.Render(fitCell(col.Title, col.Width)))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+}
+
+func (m dataTableModel) renderRow(r int) string {
+	cells := make([]string, 0, len(m.cols))
+	for i, value := range m.rows[r] {
+		if i >= len(m.cols) || m.cols[i].Width <= 0 {
+			continue
+		}
+		cells = append(cells, m.styles.Cell.Render(fitCell(value, m.cols[i].Width)))
+	}
+
+	row := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	if r == m.cursor {
+		return m.styles.Selected.Render(row)
+	}
+
+	return row
+}
+
+// fitCell truncates or pads the value to exactly width display cells.
+func fitCell(value string, width int) string {
+	if ansi.StringWidth(value) > width {
+		value = ansi.Truncate(value, width, "…")
+	}
+
+	return lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true).Render(value)
+}
+
+// SelectedRow returns the row under the cursor.
+func (m dataTableModel) SelectedRow() table.Row {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+
+	return m.rows[m.cursor]
+}
+
+// Rows returns the current rows.
+func (m dataTableModel) Rows() []table.Row { return m.rows }
+
+// Columns returns the current columns.
+func (m dataTableModel) Columns() []table.Column { return m.cols }
+
+// SetRows sets the rows keeping the cursor within bounds.
+func (m *dataTableModel) SetRows(rows []table.Row) {
+	m.rows = rows
+	m.SetCursor(m.cursor)
+}
+
+// SetColumns sets the columns.
+func (m *dataTableModel) SetColumns(cols []table.Column) { m.cols = cols }
+
+// SetStyles sets the table styles.
+func (m *dataTableModel) SetStyles(styles table.Styles) { m.styles = styles }
+
+// Height returns the number of visible rows.
+func (m dataTableModel) Height() int { return max(m.height-1, 1) }
+
+// SetHeight sets the table height including the header line.
+func (m *dataTableModel) SetHeight(h int) {
+	m.height = h
+	m.SetCursor(m.cursor)
+}
+
+// Focused returns the focus state of the table.
+func (m dataTableModel) Focused() bool { return m.focus }
+
+// Focus focuses the table, allowing navigation.
+func (m *dataTableModel) Focus() { m.focus = true }
+
+// Blur blurs the table, preventing navigation.
+func (m *dataTableModel) Blur() { m.focus = false }
+
+// Cursor returns the index of the selected row.
+func (m dataTableModel) Cursor() int { return m.cursor }
+
+// Offset returns the index of the first visible row.
+func (m dataTableModel) Offset() int { return m.start }
+
+// ColumnAt returns the index of the column drawn at the x cell of the table view,
+// or -1 if there is none.
+func (m dataTableModel) ColumnAt(x int) int {
+	left := 0
+	for i, col := range m.cols {
+		if col.Width <= 0 {
+			continue
+		}
+		right := left + col.Width + m.styles.Header.GetHorizontalFrameSize()
+		if x >= left && x < right {
+			return i
+		}
+		left = right
+	}
+
+	return -1
+}
+
+// RowAt returns the index of the row drawn at the y line of the table view,
+// or -1 if there is none. The header is drawn at line 0.
+func (m dataTableModel) RowAt(y int) int {
+	if y < 1 || y > m.Height() {
+		return -1
+	}
+
+	row := m.start + y - 1
+	if row >= len(m.rows) {
+		return -1
+	}
+
+	return row
+}
+
+// SetCursor moves the cursor to the row and scrolls it into view.
+func (m *dataTableModel) SetCursor(n int) {
+	m.cursor = clamp(n, 0, len(m.rows)-1)
+
+	if m.cursor < m.start {
+		m.start = m.cursor
+	}
+	if m.cursor >= m.start+m.Height() {
+		m.start = m.cursor - m.Height() + 1
+	}
+	m.start = clamp(m.start, 0, max(len(m.rows)-m.Height(), 0))
+}
+
+// MoveUp moves the cursor up by n rows.
+func (m *dataTableModel) MoveUp(n int) { m.SetCursor(m.cursor - n) }
+
+// MoveDown moves the cursor down by n rows.
+func (m *dataTableModel) MoveDown(n int) { m.SetCursor(m.cursor + n) }
+
+// GotoTop moves the cursor to the first row.
+func (m *dataTableModel) GotoTop() { m.SetCursor(0) }
+
+// GotoBottom moves the cursor to the last row.
+func (m *dataTableModel) GotoBottom() { m.SetCursor(len(m.rows) - 1) }
+
+func clamp(v, low, high int) int {
+	return min(max(v, low), high)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailPaneHeight is the largest number of content lines of the detail pane.
+const detailPaneHeight = 12
+
+var detailPaneStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("205")).
+	Padding(0, 2)
+
+var detailLabelStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240")).
+	Width(12)
+
+// detailClosedMsg is sent when the detail pane is dismissed.
+type detailClosedMsg struct{}
+
+// detailKillMsg is sent when the process shown in the pane should be killed.
+type detailKillMsg struct{ target Process }
+
+// detailCopyMsg is sent when the command line of the process should be copied.
+type detailCopyMsg struct{ text string }
+
+// detailFilterMsg is sent when the table should be filtered by the process PID.
+type detailFilterMsg struct{ pid int }
+
+// detailEnvMsg is sent when the environment should be loaded or hidden.
+type detailEnvMsg struct {
+	pid  int
+	show bool
+}
+
+// detailModel shows the details of a process below the table.
+type detailModel struct {
+	details  ProcessDetails
+	viewport viewport.Model
+}
+
+func newDetailModel(details ProcessDetails, width int) *detailModel {
+	m := &detailModel{details: details, viewport: viewport.New(0, detailPaneHeight)}
+	m.SetWidth(width)
+
+	return m
+}
+
+func (m *detailModel) Init() tea.Cmd { return nil }
+
+func (m *detailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.MouseMsg); ok {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	details := m.details
+
+	switch keyMsg.String() {
+	case "esc", "enter", "q":
+		return m, func() tea.Msg { return detailClosedMsg{} }
+	case "k":
+		target := Process{PID: details.PID, Name: details.Name}
+		if len(details.Sockets) > 0 {
+			target = details.Sockets[0]
+		}
+		return m, func() tea.Msg { return detailKillMsg{target: target} }
+	case "y":
+		return m, func() tea.Msg { return detailCopyMsg{text: details.Cmdline} }
+	case "p":
+		return m, func() tea.Msg { return detailFilterMsg{pid: details.PID} }
+	case "v":
+		show := details.Environ == nil
+		return m, func() tea.Msg { return detailEnvMsg{pid: details.PID, show: show} }
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+
+	return m, cmd
+}
+
+func (m *detailModel) View() string {
+	hints := presetHintStyle.Render("[k] Kill  [y] Copy command  [p] Filter by PID  [v] Environment  [↑/↓] Scroll  [Esc] Close")
+
+	return detailPaneStyle.Width(m.viewport.Width + detailPaneStyle.GetHorizontalFrameSize()).
+		Render(m.viewport.View() + "\n" + hints)
+}
+
+// SetWidth sets the outer width of the pane and rewraps the content.
+func (m *detailModel) SetWidth(width int) {
+	m.viewport.Width = max(width-detailPaneStyle.GetHorizontalFrameSize(), 10)
+	m.viewport.SetContent(renderDetails(m.details, m.viewport.Width))
+}
+
+// SetHeight sets the outer height of the pane, at most detailPaneHeight content lines are shown.
+func (m *detailModel) SetHeight(height int) {
+	// The border and the hints line take three lines.
+	m.viewport.Height = clamp(height-3, 1, detailPaneHeight)
+}
+
+// SetDetails replaces the shown details keeping the scroll position.
+func (m *detailModel) SetDetails(details ProcessDetails) {
+	m.details = details
+	m.viewport.SetContent(renderDetails(details, m.viewport.Width))
+}
+
+// renderDetails renders the details as labelled lines wrapped to the width.
+func renderDetails(d ProcessDetails, width int) string {
+	var lines []string
+
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		valueWidth := max(width-detailLabelStyle.GetWidth(), 10)
+		wrapped := lipgloss.NewStyle().Width(valueWidth).Render(value)
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, detailLabelStyle.Render(label), wrapped))
+	}
+
+	field("Process", fmt.Sprintf("%s (%d)", displayName(d.Name), d.PID))
+	field("Command", d.Cmdline)
+	field("Executable", d.Exe)
+	field("User", d.User)
+	field("Directory", d.Cwd)
+	if !d.StartTime.IsZero() {
+		field("Started", fmt.Sprintf("%s (%s ago)", d.StartTime.Format(time.DateTime), time.Since(d.StartTime).Round(time.Second)))
+	}
+
+	if len(d.Parents) > 0 {
+		chain := make([]string, len(d.Parents))
+		for i, parent := range d.Parents {
+			chain[i] = fmt.Sprintf("%s (%d)", displayName(parent.Name), parent.PID)
+		}
+		field("Parents", strings.Join(chain, " ← "))
+	}
+
+	usage := fmt.Sprintf("CPU %.1f%%", d.CPUPercent)
+	if d.MemoryRSS > 0 {
+		usage += fmt.Sprintf("  RSS %s  VMS %s", formatBytes(d.MemoryRSS), formatBytes(d.MemoryVMS))
+	}
+	if d.Threads > 0 {
+		usage += "  Threads " + strconv.Itoa(d.Threads)
+	}
+	if d.OpenFiles > 0 {
+		usage += "  Open files " + strconv.Itoa(d.OpenFiles)
+	}
+	field("Usage", usage)
+
+	sockets := make([]string, len(d.Sockets))
+	for i, socket := range d.Sockets {
+		sockets[i] = fmt.Sprintf("%s %s %s", socket.Protocol, renderExposure(socket.LocalAddr, socket.Exposure), socket.Status)
+		if socket.RemoteAddr != "" {
+			sockets[i] += " → " + socket.RemoteAddr
+		}
+		if socket.RecvQ > 0 || socket.SendQ > 0 {
+			sockets[i] += fmt.Sprintf("  Recv-Q %d  Send-Q %d", socket.RecvQ, socket.SendQ)
+		}
+		if socket.RTT > 0 {
+			sockets[i] += fmt.Sprintf("  RTT %.2fms  Retrans %d", socket.RTT, socket.Retransmits)
+		}
+		if socket.BytesSent > 0 || socket.BytesReceived > 0 {
+			sockets[i] += fmt.Sprintf("  ↑%s ↓%s", formatBytes(socket.BytesSent), formatBytes(socket.BytesReceived))
+		}
+		if socket.RxRate > 0 || socket.TxRate > 0 {
+			sockets[i] += fmt.Sprintf("  RX %s  TX %s", formatRate(socket.RxRate), formatRate(socket.TxRate))
+		}
+		if socket.BacklogFull() {
+			sockets[i] += "  " + failureStyle.Render("backlog full")
+		}
+	}
+	field("Sockets", strings.Join(sockets, "\n"))
+
+	switch {
+	case d.Environ == nil:
+	case len(d.Environ) == 0:
+		field("Environment", "(empty)")
+	default:
+		field("Environment", strings.Join(d.Environ, "\n"))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historySparklineWidth is the number of samples drawn in the history pane sparklines.
+const historySparklineWidth = 30
+
+// historyClosedMsg is sent when the history pane is dismissed.
+type historyClosedMsg struct{}
+
+// historyModel shows when the port of a socket was first seen and by whom,
+// and the recent activity of its process.
+type historyModel struct {
+	process Process
+	port    PortHistory
+	seen    bool
+	samples []ProcessSample
+}
+
+func newHistoryModel(pm *ProcessManager, process Process) *historyModel {
+	port, seen := pm.PortHistory(process.Protocol, process.Port)
+
+	return &historyModel{
+		process: process,
+		port:    port,
+		seen:    seen,
+		samples: pm.ProcessHistory(process.PID),
+	}
+}
+
+func (m *historyModel) Init() tea.Cmd { return nil }
+
+func (m *historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "enter", "q", "h":
+		return m, func() tea.Msg { return historyClosedMsg{} }
+	}
+
+	return m, nil
+}
+
+func (m *historyModel) View() string {
+	lines := []string{fmt.Sprintf("History of %s port %d", strings.ToUpper(newPortKey(m.process.Protocol, m.process.Port).transport), m.process.Port), ""}
+
+	if m.seen {
+		lines = append(lines, detailLabelStyle.Render("First seen")+formatSeen(m.port.FirstSeen))
+		for i, owner := range m.port.Owners {
+			label := ""
+			if i == 0 {
+				label = "Owners"
+			}
+			lines = append(lines, detailLabelStyle.Render(label)+fmt.Sprintf("%s (%d)  %s – %s",
+				displayName(owner.Name), owner.PID, owner.FirstSeen.Format(time.TimeOnly), owner.LastSeen.Format(time.TimeOnly)))
+		}
+	} else {
+		lines = append(lines, presetHintStyle.Render("The port was not seen listening"))
+	}
+
+	lines = append(lines, "")
+	if len(m.samples) == 0 {
+		lines = append(lines, presetHintStyle.Render("No samples of "+displayName(m.process.Name)+" yet"))
+	} else {
+		last := m.samples[len(m.samples)-1]
+		lines = append(lines,
+			detailLabelStyle.Render("Sockets")+sparkline(sampleValues(m.samples, sampleSockets), historySparklineWidth)+" "+strconv.Itoa(last.Sockets),
+			detailLabelStyle.Render("CPU")+sparkline(sampleValues(m.samples, sampleCPU), historySparklineWidth)+fmt.Sprintf(" %.1f%%", last.CPUPercent),
+			detailLabelStyle.Render("Memory")+sparkline(sampleValues(m.samples, sampleMemory), historySparklineWidth)+" "+formatBytes(last.MemoryRSS),
+			presetHintStyle.Render(fmt.Sprintf("Since %s", m.samples[0].At.Format(time.TimeOnly))),
+		)
+	}
+
+	lines = append(lines, "", presetHintStyle.Render("[Esc] Close"))
+
+	return confirmBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// formatSeen formats the time with how long ago it was.
+func formatSeen(t time.Time) string {
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.DateTime), time.Since(t).Round(time.Second))
+}
+
+// sampleValues returns the values of the samples selected by value,
+// such as sampleSockets, sampleCPU or sampleMemory.
+func sampleValues(samples []ProcessSample, value func(ProcessSample) float64) []float64 {
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = value(sample)
+	}
+
+	return values
+}
+
+func sampleSockets(sample ProcessSample) float64 { return float64(sample.Sockets) }
+
+func sampleCPU(sample ProcessSample) float64 { return sample.CPUPercent }
+
+func sampleMemory(sample ProcessSample) float64 { return float64(sample.MemoryRSS) }
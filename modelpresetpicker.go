@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var presetSelectedStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("205")).
+	Bold(true)
+
+var presetHintStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240"))
+
+// presetPickedMsg is sent when a preset is chosen in the picker.
+type presetPickedMsg struct{ preset SearchPreset }
+
+// presetSaveMsg is sent when the current search should be saved as a preset.
+type presetSaveMsg struct{ name string }
+
+// presetDeleteMsg is sent when a preset should be removed.
+type presetDeleteMsg struct{ name string }
+
+// presetClosedMsg is sent when the picker is dismissed.
+type presetClosedMsg struct{}
+
+// presetPickerModel lists saved presets and lets the user apply,
+// delete or create one from the current search.
+type presetPickerModel struct {
+	presets []SearchPreset
+	cursor  int
+	naming  bool
+	name    textinput.Model
+}
+
+func newPresetPickerModel(presets []SearchPreset) *presetPickerModel {
+	name := textinput.New()
+	name.Placeholder = "preset name"
+	name.Prompt = "Name: "
+
+	return &presetPickerModel{presets: presets, name: name}
+}
+
+func (m *presetPickerModel) Init() tea.Cmd { return nil }
+
+func (m *presetPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.naming {
+		switch keyMsg.String() {
+		case "esc":
+			m.naming = false
+			m.name.Blur()
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(m.name.Value())
+			if name == "" {
+				return m, nil
+			}
+			return m, func() tea.Msg { return presetSaveMsg{name: name} }
+		}
+
+		var cmd tea.Cmd
+		m.name, cmd = m.name.Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.presets)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor < len(m.presets) {
+			preset := m.presets[m.cursor]
+			return m, func() tea.Msg { return presetPickedMsg{preset: preset} }
+		}
+	case "d", "delete":
+		if m.cursor < len(m.presets) {
+			name := m.presets[m.cursor].Name
+			return m, func() tea.Msg { return presetDeleteMsg{name: name} }
+		}
+	case "n":
+		m.naming = true
+		m.name.SetValue("")
+		return m, m.name.Focus()
+	case "esc", "q", "p":
+		return m, func() tea.Msg { return presetClosedMsg{} }
+	}
+
+	return m, nil
+}
+
+func (m *presetPickerModel) View() string {
+	lines := []string{"Filter presets", ""}
+
+	if len(m.presets) == 0 {
+		lines = append(lines, presetHintStyle.Render("No presets yet"))
+	}
+
+	for i, preset := range m.presets {
+		line := fmt.Sprintf("  %s  %s", preset.Name, presetHintStyle.Render(describePreset(preset)))
+		if i == m.cursor {
+			line = presetSelectedStyle.Render("> "+preset.Name) + "  " + presetHintStyle.Render(describePreset(preset))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	if m.naming {
+		lines = append(lines, m.name.View(), presetHintStyle.Render("[Enter] Save  [Esc] Back"))
+	} else {
+		lines = append(lines, presetHintStyle.Render("[Enter] Apply  [n] Save current  [d] Delete  [Esc] Close"))
+	}
+
+	return confirmBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// SetPresets replaces the listed presets keeping the cursor in range.
+func (m *presetPickerModel) SetPresets(presets []SearchPreset) {
+	m.presets = presets
+	m.naming = false
+	m.cursor = clamp(m.cursor, 0, max(len(presets)-1, 0))
+}
+
+// describePreset returns a short summary of the preset query and filters.
+func describePreset(preset SearchPreset) string {
+	parts := make([]string, 0, 3)
+	if preset.Query != "" {
+		parts = append(parts, fmt.Sprintf("%q", preset.Query))
+	}
+	if preset.Mode != "" {
+		parts = append(parts, preset.Mode)
+	}
+	if len(preset.Filters) > 0 {
+		parts = append(parts, strings.Join(preset.Filters, ","))
+	}
+	return strings.Join(parts, " ")
+}
@@ -11,20 +11,37 @@ var searchInputStyle = lipgloss.NewStyle().
 	BorderForeground(lipgloss.Color("240")).
 	Padding(0, 2)
 
+var searchErrorStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("203"))
+
+var searchHighlightStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214")).
+	Bold(true).
+	Underline(true)
+
 type searchInputModel struct {
 	input textinput.Model
 	width int
+	err   error
+	mode  searchMode
+
+	// history holds previous queries, the most recent first.
+	history []SearchHistoryEntry
+	// historyIndex points to the shown history entry, -1 means the draft.
+	historyIndex int
+	draft        string
+	draftMode    searchMode
 }
 
 func newSearchInputModel() *searchInputModel {
 	input := textinput.New()
-	input.Placeholder = "Search processes, ports, or addresses..."
+	input.Placeholder = "Search processes, ports, or addresses... (port:80 proc:node -state:listen | pid:1234)"
 	input.PromptStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("205"))
+		Foreground(lipgloss.Color(activeTheme.Accent))
 	input.TextStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("205"))
+		Foreground(lipgloss.Color(activeTheme.Accent))
 
-	return &searchInputModel{input: input}
+	return &searchInputModel{input: input, historyIndex: -1}
 }
 
 func (m *searchInputModel) Init() tea.Cmd { return textinput.Blink }
@@ -32,23 +49,94 @@ func (m *searchInputModel) Init() tea.Cmd { return textinput.Blink }
 func (m *searchInputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up":
+			m.showHistory(m.historyIndex + 1)
+			return m, nil
+		case "down":
+			m.showHistory(m.historyIndex - 1)
+			return m, nil
+		}
+	}
+
 	m.input, cmd = m.input.Update(msg)
 
 	return m, cmd
 }
 
 func (m *searchInputModel) View() string {
+	content := m.input.View()
+	if m.err != nil {
+		content += "\n" + searchErrorStyle.Render("✗ "+m.err.Error())
+	}
+
 	if m.width > 0 {
 		m.input.Width = m.width - searchInputStyle.GetHorizontalFrameSize()
 
 		return searchInputStyle.Width(m.width).
-			Render(m.input.View())
+			Render(content)
+	}
+
+	return searchInputStyle.Render(content)
+}
+
+func (m *searchInputModel) Focus() tea.Cmd     { return m.input.Focus() }
+func (m *searchInputModel) Value() string      { return m.input.Value() }
+func (m *searchInputModel) SetValue(s string)  { m.input.SetValue(s) }
+func (m *searchInputModel) SetWidth(w int)     { m.width = w }
+func (m *searchInputModel) SetError(err error) { m.err = err }
+func (m *searchInputModel) Mode() searchMode   { return m.mode }
+
+// SetHistory sets the previous queries, the most recent first, and resets navigation.
+func (m *searchInputModel) SetHistory(history []SearchHistoryEntry) {
+	m.history = history
+	m.historyIndex = -1
+}
+
+// showHistory replaces the input and the mode with the history entry, index -1 restores the draft.
+func (m *searchInputModel) showHistory(index int) {
+	if index < -1 || index >= len(m.history) {
+		return
+	}
+
+	if m.historyIndex == -1 {
+		m.draft = m.input.Value()
+		m.draftMode = m.mode
+	}
+
+	m.historyIndex = index
+	if index == -1 {
+		m.input.SetValue(m.draft)
+		m.SetMode(m.draftMode)
+	} else {
+		// The mode was validated when the store was loaded.
+		mode, _ := parseSearchMode(m.history[index].Mode)
+		m.input.SetValue(m.history[index].Query)
+		m.SetMode(mode)
+	}
+	m.input.CursorEnd()
+}
+
+// ToggleMode switches to the mode or back to the query mode if it is already active.
+func (m *searchInputModel) ToggleMode(mode searchMode) {
+	if m.mode == mode {
+		mode = searchModeQuery
 	}
 
-	return searchInputStyle.Render(m.input.View())
+	m.SetMode(mode)
 }
 
-func (m *searchInputModel) Focus() tea.Cmd    { return m.input.Focus() }
-func (m *searchInputModel) Value() string     { return m.input.Value() }
-func (m *searchInputModel) SetValue(s string) { m.input.SetValue(s) }
-func (m *searchInputModel) SetWidth(w int)    { m.width = w }
+// SetMode sets the search mode and the matching prompt.
+func (m *searchInputModel) SetMode(mode searchMode) {
+	m.mode = mode
+
+	switch mode {
+	case searchModeRegex:
+		m.input.Prompt = "regex> "
+	case searchModeFuzzy:
+		m.input.Prompt = "fuzzy> "
+	default:
+		m.input.Prompt = "> "
+	}
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var baseStyle = lipgloss.NewStyle().
@@ -27,6 +31,15 @@ var headerRightStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("240")).
 	Padding(0, 2)
 
+// compactHeight is the terminal height below which the compact layout is used.
+const compactHeight = 16
+
+// minTableHeight is the smallest table height: the header and one row.
+const minTableHeight = 2
+
+// statusBarHeight is the number of lines of the status bar.
+const statusBarHeight = 1
+
 var confirmBoxStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.RoundedBorder()).
 	BorderForeground(lipgloss.Color("205")).
@@ -37,6 +50,7 @@ type filterState struct {
 	udpOnly         bool
 	listenOnly      bool
 	establishedOnly bool
+	exposedOnly     bool
 }
 
 type statusKind int
@@ -75,11 +89,16 @@ func (f *filterState) toggleEstablished() {
 	}
 }
 
+func (f *filterState) toggleExposed() {
+	f.exposedOnly = !f.exposedOnly
+}
+
 func (f *filterState) clear() {
 	f.tcpOnly = false
 	f.udpOnly = false
 	f.listenOnly = false
 	f.establishedOnly = false
+	f.exposedOnly = false
 }
 
 func (f filterState) allows(p Process) bool {
@@ -98,12 +117,15 @@ func (f filterState) allows(p Process) bool {
 	if f.establishedOnly && status != "ESTABLISHED" {
 		return false
 	}
+	if f.exposedOnly && !isExternallyReachable(p.Exposure) {
+		return false
+	}
 
 	return true
 }
 
 func (f filterState) activeLabels() []string {
-	labels := make([]string, 0, 4)
+	labels := make([]string, 0, 5)
 	if f.tcpOnly {
 		labels = append(labels, "TCP")
 	}
@@ -116,19 +138,49 @@ func (f filterState) activeLabels() []string {
 	if f.establishedOnly {
 		labels = append(labels, "ESTABLISHED")
 	}
+	if f.exposedOnly {
+		labels = append(labels, "EXPOSED")
+	}
 	return labels
 }
 
+// parseFilterState enables the filter toggles by their names:
+// "tcp", "udp", "listen", "established" and "exposed".
+func parseFilterState(names []string) (filterState, error) {
+	var filters filterState
+
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "tcp":
+			filters.tcpOnly = true
+		case "udp":
+			filters.udpOnly = true
+		case "listen":
+			filters.listenOnly = true
+		case "established":
+			filters.establishedOnly = true
+		case "exposed":
+			filters.exposedOnly = true
+		default:
+			return filters, fmt.Errorf("invalid filter: %s", name)
+		}
+	}
+
+	return filters, nil
+}
+
 type tableModel struct {
 	pm               *ProcessManager
-	table            table.Model
+	table            dataTableModel
 	searchInput      *searchInputModel
 	width            int
 	height           int
 	tableViewWidth   int
 	showSearch       bool
 	searchQuery      string
+	query            searchQuery
 	allProcesses     []Process
+	visibleProcesses []Process
 	filteredRowCount int
 	filters          filterState
 	statusMessage    string
@@ -136,24 +188,63 @@ type tableModel struct {
 	statusExpires    time.Time
 	confirmKill      bool
 	confirmTarget    Process
-	horizontalScroll int
+	// confirmRisk explains why the target is protected, killing it then needs
+	// a second confirmation, recorded by riskConfirmed, which is typing the
+	// process name into typedConfirmation.
+	confirmRisk       KillRisk
+	riskConfirmed     bool
+	typedConfirmation string
+	// horizontalScroll is the offset in display cells of the cut off cells,
+	// limited to maxHorizontalScroll, the widest overflow of a visible cell.
+	horizontalScroll    int
+	maxHorizontalScroll int
+	// columnOverflow holds the widest overflow of each visible column.
+	columnOverflow map[string]int
+	// expandedColumn is the column given all the spare width.
+	expandedColumn string
+	store          *SearchStore
+	showPresets    bool
+	presetPicker   *presetPickerModel
+	keys           keyMap
+	columns        []string
+	showColumns    bool
+	columnChooser  *columnChooserModel
+	showSummary    bool
+	showHistory    bool
+	history        *historyModel
+	// traffic and samples cache the data of the sparkline columns, by socket and
+	// by PID, until the processes are fetched again after historyRefreshed.
+	traffic          map[rowSocket][]float64
+	samples          map[int][]ProcessSample
+	historyRefreshed time.Time
+	showDetail       bool
+	detail           *detailModel
+	// pendingCopy is set after the copy key until the copy target key is pressed.
+	pendingCopy bool
+	// sortColumn is the column the rows are sorted by, the search order is kept when empty.
+	sortColumn string
+	sortDesc   bool
+	layout     screenLayout
 }
 
-func newTableModel(pm *ProcessManager) *tableModel {
-	columns := []table.Column{
-		{Title: "PID", Width: 5},
-		{Title: "Protocol", Width: 8},
-		{Title: "Port", Width: 8},
-		{Title: "Status", Width: 15},
-		{Title: "Local Address", Width: 15},
-		{Title: "Process", Width: 15},
+func newTableModel(pm *ProcessManager, store *SearchStore, config Config) (*tableModel, error) {
+	keys, err := config.keyMap()
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
 	}
 
-	t := table.New(
-		table.WithColumns(columns),
-		table.WithFocused(true),
-		table.WithHeight(10),
-	)
+	filters, err := config.filterState()
+	if err != nil {
+		return nil, fmt.Errorf("defaults: %w", err)
+	}
+
+	mode, err := parseSearchMode(config.Defaults.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("defaults: %w", err)
+	}
+
+	t := newDataTableModel(nil, 10)
+	t.KeyMap = keys.tableKeyMap()
 
 	s := table.DefaultStyles()
 
@@ -168,6 +259,8 @@ func newTableModel(pm *ProcessManager) *tableModel {
 	// 	Background(lipgloss.Color("57")).
 	// 	Bold(false)
 
+	s.Selected = selectedRowStyle(s.Selected)
+
 	t.SetStyles(s)
 
 	// Initialize search input
@@ -178,11 +271,22 @@ func newTableModel(pm *ProcessManager) *tableModel {
 		table:       t,
 		searchInput: searchInput,
 		showSearch:  false,
+		store:       store,
+		filters:     filters,
+		keys:        keys,
+	}
+	m.setColumns(config.columns())
+
+	if config.Defaults.Query != "" {
+		m.searchInput.SetMode(mode)
+		m.searchInput.SetValue(config.Defaults.Query)
+		m.setSearchQuery(config.Defaults.Query)
+		if m.searchInput.err != nil {
+			return nil, fmt.Errorf("defaults: query: %w", m.searchInput.err)
+		}
 	}
-	m.filters.tcpOnly = true
-	m.filters.listenOnly = true
 
-	return &m
+	return &m, nil
 }
 
 func (m *tableModel) setStatusMessage(text string, kind statusKind) {
@@ -191,24 +295,41 @@ func (m *tableModel) setStatusMessage(text string, kind statusKind) {
 	m.statusExpires = time.Now().Add(4 * time.Second)
 }
 
+// setColumns shows the columns in the given order.
+func (m *tableModel) setColumns(names []string) {
+	m.columns = names
+
+	columns := make([]table.Column, len(names))
+	for i, name := range names {
+		columns[i] = table.Column{Title: m.columnTitle(name), Width: tableColumns[name].min}
+	}
+
+	m.table.SetColumns(columns)
+	m.updateTableSize()
+}
+
+// columnTitle returns the column header with the sort direction marker.
+func (m *tableModel) columnTitle(name string) string {
+	title := tableColumns[name].title
+	if name != m.sortColumn {
+		return title
+	}
+
+	if m.sortDesc {
+		return title + " ▼"
+	}
+
+	return title + " ▲"
+}
+
 func (m *tableModel) updateTableSize() {
 	if m.width <= 0 {
 		return
 	}
 
-	type columnSpec struct {
-		title  string
-		min    int
-		weight int
-	}
-
-	specs := []columnSpec{
-		{title: "PID", min: 6, weight: 0},
-		{title: "Protocol", min: 8, weight: 0},
-		{title: "Port", min: 6, weight: 0},
-		{title: "Status", min: 12, weight: 1},
-		{title: "Local Address", min: 18, weight: 0},
-		{title: "Process", min: 18, weight: 6},
+	specs := make([]tableColumn, len(m.columns))
+	for i, name := range m.columns {
+		specs[i] = tableColumns[name]
 	}
 
 	frameWidth := baseStyle.GetHorizontalFrameSize()
@@ -221,6 +342,16 @@ func (m *tableModel) updateTableSize() {
 		availableWidth = 0
 	}
 
+	// The expanded column takes all the spare width.
+	if slices.Contains(m.columns, m.expandedColumn) {
+		for i, name := range m.columns {
+			specs[i].weight = 0
+			if name == m.expandedColumn {
+				specs[i].weight = 1
+			}
+		}
+	}
+
 	totalMin := 0
 	totalWeight := 0
 	for _, spec := range specs {
@@ -275,7 +406,7 @@ func (m *tableModel) updateTableSize() {
 		if width < spec.min {
 			width = spec.min
 		}
-		columns[i] = table.Column{Title: spec.title, Width: width}
+		columns[i] = table.Column{Title: m.columnTitle(m.columns[i]), Width: width}
 		totalWidth += width
 	}
 
@@ -293,6 +424,28 @@ func (m *tableModel) Init() tea.Cmd {
 // tickMsg is a message that triggers a UI refresh.
 type tickMsg time.Time
 
+// freePortsMsg carries the result of a free port lookup. The ports may be
+// a partial result when the lookup timed out.
+type freePortsMsg struct {
+	protocol string
+	port     int
+	ports    []int
+	err      error
+}
+
+// detailLoadedMsg carries the details of a process loaded by loadDetail.
+type detailLoadedMsg struct {
+	details ProcessDetails
+	refresh bool
+	err     error
+}
+
+// killRiskMsg carries the kill risk of a process looked up by startKill.
+type killRiskMsg struct {
+	target Process
+	risk   KillRisk
+}
+
 func (m *tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -303,117 +456,276 @@ func (m *tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return tickMsg(t)
 		})
 
+	case freePortsMsg:
+		m.showFreePorts(msg)
+		return m, nil
+
+	case presetPickedMsg:
+		m.showPresets = false
+		if err := m.applyPreset(msg.preset); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Preset failed: %v", err), statusKindError)
+			return m, nil
+		}
+		m.setStatusMessage("Applied preset "+msg.preset.Name, statusKindInfo)
+		return m, nil
+
+	case presetSaveMsg:
+		m.store.SetPreset(newSearchPreset(msg.name, m.searchQuery, m.searchInput.Mode(), m.filters))
+		m.presetPicker.SetPresets(m.store.Presets)
+		m.saveStore("Saved preset " + msg.name)
+		return m, nil
+
+	case presetDeleteMsg:
+		m.store.DeletePreset(msg.name)
+		m.presetPicker.SetPresets(m.store.Presets)
+		m.saveStore("Deleted preset " + msg.name)
+		return m, nil
+
+	case presetClosedMsg:
+		m.showPresets = false
+		return m, nil
+
+	case columnsChosenMsg:
+		m.showColumns = false
+		m.setColumns(msg.columns)
+		if err := SaveConfigColumns(configPath, msg.columns); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Save columns failed: %v", err), statusKindError)
+			return m, nil
+		}
+		m.setStatusMessage("Columns saved", statusKindInfo)
+		return m, nil
+
+	case columnsClosedMsg:
+		m.showColumns = false
+		return m, nil
+
+	case historyClosedMsg:
+		m.showHistory = false
+		return m, nil
+
+	case detailClosedMsg:
+		m.showDetail = false
+		return m, nil
+
+	case detailKillMsg:
+		m.showDetail = false
+		return m, m.startKill(msg.target)
+
+	case killRiskMsg:
+		m.confirmKillRisk(msg)
+		return m, nil
+
+	case detailCopyMsg:
+		if msg.text == "" {
+			m.setStatusMessage("Copy failed: command line is not available", statusKindError)
+			return m, nil
+		}
+		if err := copyToClipboard(msg.text); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Copy failed: %v", err), statusKindError)
+			return m, nil
+		}
+		m.setStatusMessage("Copied command line", statusKindInfo)
+		return m, nil
+
+	case detailFilterMsg:
+		m.showDetail = false
+		query := "pid:" + strconv.Itoa(msg.pid)
+		m.searchInput.SetMode(searchModeQuery)
+		m.searchInput.SetValue(query)
+		m.setSearchQuery(query)
+		return m, nil
+
+	case detailEnvMsg:
+		return m, m.loadDetail(msg.pid, msg.show, true)
+
+	case detailLoadedMsg:
+		m.showLoadedDetail(msg)
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.horizontalScroll = 0 // Reset horizontal scroll on resize
 		m.updateTableSize()
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case tea.KeyMsg:
+		if m.confirmKill && m.riskConfirmed {
+			m.typeConfirmation(msg)
+			return m, nil
+		}
+
 		if m.confirmKill {
 			switch msg.String() {
 			case "y", "enter":
-				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-				pid := m.confirmTarget.PID
-				name := m.confirmTarget.Name
-				m.confirmKill = false
-				defer cancel()
-				if err := m.pm.KillProcess(ctx, pid); err != nil {
-					m.setStatusMessage(fmt.Sprintf("Kill failed: %v", err), statusKindError)
-					return m, nil
-				}
-				if name != "" {
-					m.setStatusMessage(fmt.Sprintf("Killed %s (%d)", name, pid), statusKindInfo)
-				} else {
-					m.setStatusMessage(fmt.Sprintf("Killed PID %d", pid), statusKindInfo)
-				}
+				m.killConfirmed()
 				return m, nil
 			case "n", "esc":
-				m.confirmKill = false
-				m.setStatusMessage("Kill cancelled", statusKindInfo)
+				m.cancelKill()
+				return m, nil
+			}
+		}
+
+		if m.showPresets {
+			_, cmd = m.presetPicker.Update(msg)
+			return m, cmd
+		}
+
+		if m.showColumns {
+			_, cmd = m.columnChooser.Update(msg)
+			return m, cmd
+		}
+
+		if m.showHistory {
+			_, cmd = m.history.Update(msg)
+			return m, cmd
+		}
+
+		if m.showDetail {
+			_, cmd = m.detail.Update(msg)
+			return m, cmd
+		}
+
+		if m.pendingCopy {
+			m.pendingCopy = false
+			target, ok := copyTargets[msg.String()]
+			if !ok {
+				m.setStatusMessage("Copy cancelled", statusKindInfo)
 				return m, nil
 			}
+			m.copySelected(target)
+			return m, nil
 		}
 
 		if m.showSearch {
 			switch msg.String() {
+			case "ctrl+r":
+				m.searchInput.ToggleMode(searchModeRegex)
+				m.setSearchQuery(m.searchInput.Value())
+				return m, nil
+
+			case "ctrl+t":
+				m.searchInput.ToggleMode(searchModeFuzzy)
+				m.setSearchQuery(m.searchInput.Value())
+				return m, nil
+
 			case "esc":
 				m.showSearch = false
 				m.searchInput.SetValue("")
-				m.searchQuery = ""
+				m.setSearchQuery("")
 				m.table.Focus()
 				return m, nil
 
 			case "enter":
 				m.showSearch = false
-				m.searchQuery = m.searchInput.Value()
+				m.setSearchQuery(m.searchInput.Value())
 				m.table.Focus()
+				if m.searchQuery != "" {
+					m.store.AddHistory(m.searchQuery, m.searchInput.Mode())
+					m.saveStore("")
+				}
 				return m, nil
 			}
 			// Update search input and apply search in real-time
 			var updatedModel tea.Model
 			updatedModel, cmd = m.searchInput.Update(msg)
 			m.searchInput = updatedModel.(*searchInputModel)
-			m.searchQuery = m.searchInput.Value()
+			m.setSearchQuery(m.searchInput.Value())
 			return m, cmd
 		}
 
-		switch msg.String() {
-		case "/":
+		switch {
+		case key.Matches(msg, m.keys.Search):
 			m.showSearch = true
+			m.searchInput.SetHistory(m.store.History)
 			m.searchInput.Focus()
 			return m, textinput.Blink
-		case "t":
+		case key.Matches(msg, m.keys.Presets):
+			m.presetPicker = newPresetPickerModel(m.store.Presets)
+			m.showPresets = true
+			return m, nil
+		case key.Matches(msg, m.keys.Columns):
+			m.columnChooser = newColumnChooserModel(m.columns)
+			m.showColumns = true
+			return m, nil
+		case key.Matches(msg, m.keys.History):
+			selected, ok := m.selectedProcess()
+			if !ok {
+				m.setStatusMessage("No process selected", statusKindError)
+				return m, nil
+			}
+			m.history = newHistoryModel(m.pm, selected)
+			m.showHistory = true
+			return m, nil
+		case key.Matches(msg, m.keys.Summary):
+			m.showSummary = !m.showSummary
+			return m, nil
+		case key.Matches(msg, m.keys.TCP):
 			m.filters.toggleTCP()
 			return m, nil
-		case "u":
+		case key.Matches(msg, m.keys.UDP):
 			m.filters.toggleUDP()
 			return m, nil
-		case "l":
+		case key.Matches(msg, m.keys.Listen):
 			m.filters.toggleListen()
 			return m, nil
-		case "e":
+		case key.Matches(msg, m.keys.Established):
 			m.filters.toggleEstablished()
 			return m, nil
-		case "x":
+		case key.Matches(msg, m.keys.Exposed):
+			m.filters.toggleExposed()
+			return m, nil
+		case key.Matches(msg, m.keys.Clear):
 			m.filters.clear()
 			return m, nil
-		case "k":
-			row := m.table.SelectedRow()
-			if len(row) == 0 {
+		case key.Matches(msg, m.keys.Kill):
+			target, ok := m.selectedProcess()
+			if !ok {
 				m.setStatusMessage("No process selected", statusKindError)
 				return m, nil
 			}
-			pid, err := strconv.Atoi(row[0])
-			if err != nil {
-				m.setStatusMessage("Invalid PID", statusKindError)
+			return m, m.startKill(target)
+
+		case key.Matches(msg, m.keys.Free):
+			selected, ok := m.selectedProcess()
+			if !ok {
+				m.setStatusMessage("No process selected", statusKindError)
 				return m, nil
 			}
-			target := Process{PID: pid}
-			if len(row) > 5 {
-				target.Name = row[5]
-			}
-			if len(row) > 3 {
-				target.Status = row[3]
-			}
-			if len(row) > 4 {
-				target.LocalAddr = row[4]
+			m.setStatusMessage(fmt.Sprintf("Looking for free ports near %d...", selected.Port), statusKindInfo)
+			return m, m.suggestFreePorts(selected.Port, selected.Protocol)
+
+		case key.Matches(msg, m.keys.Copy):
+			if _, ok := m.selectedProcess(); !ok {
+				m.setStatusMessage("No process selected", statusKindError)
+				return m, nil
 			}
-			m.confirmKill = true
-			m.confirmTarget = target
+			m.pendingCopy = true
 			return m, nil
 
-		case "shift+left":
+		case key.Matches(msg, m.keys.Save):
+			m.saveSnapshot()
+			return m, nil
+
+		case key.Matches(msg, m.keys.ScrollLeft):
 			if m.horizontalScroll > 0 {
 				m.horizontalScroll--
 			}
 			return m, nil
 
-		case "shift+right":
-			m.horizontalScroll++
+		case key.Matches(msg, m.keys.ScrollRight):
+			if m.horizontalScroll < m.maxHorizontalScroll {
+				m.horizontalScroll++
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Expand):
+			m.expandNextColumn()
 			return m, nil
 
-		case "esc":
+		case msg.String() == "esc":
 			if m.horizontalScroll > 0 {
 				m.horizontalScroll = 0
 				return m, nil
@@ -424,13 +736,16 @@ func (m *tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.table.Focus()
 			}
 
-		case "q", "ctrl+c":
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
-		case "enter":
-			return m, tea.Batch(
-				tea.Printf("Let's go to %s!", m.table.SelectedRow()[1]),
-			)
+		case msg.String() == "enter":
+			selected, ok := m.selectedProcess()
+			if !ok {
+				m.setStatusMessage("No process selected", statusKindError)
+				return m, nil
+			}
+			return m, m.loadDetail(selected.PID, false, false)
 		}
 	}
 
@@ -439,6 +754,253 @@ func (m *tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// selectedProcess returns the process under the table cursor.
+func (m *tableModel) selectedProcess() (Process, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.visibleProcesses) {
+		return Process{}, false
+	}
+
+	return m.visibleProcesses[cursor], true
+}
+
+// copySelected copies the target data of the selected process to the clipboard.
+func (m *tableModel) copySelected(target string) {
+	selected, ok := m.selectedProcess()
+	if !ok {
+		m.setStatusMessage("No process selected", statusKindError)
+		return
+	}
+
+	text, description, err := copyText(target, selected, m.columns)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Copy failed: %v", err), statusKindError)
+		return
+	}
+
+	if err := copyToClipboard(text); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Copy failed: %v", err), statusKindError)
+		return
+	}
+
+	m.setStatusMessage("Copied "+description+" of "+displayName(selected.Name), statusKindInfo)
+}
+
+// startKill looks up the kill risk of the target process in the background,
+// the confirmation is asked once it is known.
+func (m *tableModel) startKill(target Process) tea.Cmd {
+	if m.pm.Origin() != nil {
+		m.setStatusMessage("Kill is "+ErrReadOnly.Error(), statusKindError)
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		risk, _ := m.pm.KillRisk(ctx, target.PID)
+
+		return killRiskMsg{target: target, risk: risk}
+	}
+}
+
+// confirmKillRisk asks for confirmation to kill the target process of the message,
+// unless its risk refuses the kill.
+func (m *tableModel) confirmKillRisk(msg killRiskMsg) {
+	if msg.risk.Refused {
+		m.setStatusMessage("Kill refused: "+msg.risk.Reason, statusKindError)
+		return
+	}
+
+	m.confirmKill = true
+	m.confirmTarget = msg.target
+	m.confirmRisk = msg.risk
+	m.riskConfirmed = false
+	m.typedConfirmation = ""
+}
+
+// killConfirmed kills the process awaiting confirmation. A protected process is
+// killed on the second confirmation, once its name is typed.
+func (m *tableModel) killConfirmed() {
+	if m.confirmRisk.Reason != "" {
+		if !m.riskConfirmed {
+			m.riskConfirmed = true
+			m.typedConfirmation = ""
+			return
+		}
+		if m.typedConfirmation != confirmationWord(m.confirmTarget) {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	pid := m.confirmTarget.PID
+	name := m.confirmTarget.Name
+	m.confirmKill = false
+
+	if err := m.pm.KillProcess(ctx, pid, m.riskConfirmed); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Kill failed: %v", err), statusKindError)
+		return
+	}
+
+	if name != "" {
+		m.setStatusMessage(fmt.Sprintf("Killed %s (%d)", name, pid), statusKindInfo)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("Killed PID %d", pid), statusKindInfo)
+	}
+}
+
+// typeConfirmation handles the keys of the second confirmation of a protected process.
+// Typing the name, unlike pressing the same key twice, can't happen by accident.
+func (m *tableModel) typeConfirmation(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.killConfirmed()
+	case tea.KeyEsc:
+		m.cancelKill()
+	case tea.KeyBackspace:
+		runes := []rune(m.typedConfirmation)
+		if len(runes) > 0 {
+			m.typedConfirmation = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.typedConfirmation += string(msg.Runes)
+	}
+}
+
+// cancelKill dismisses the kill confirmation.
+func (m *tableModel) cancelKill() {
+	m.confirmKill = false
+	m.setStatusMessage("Kill cancelled", statusKindInfo)
+}
+
+// loadDetail loads the details of the process in the background, walking the
+// parents, command line and environment may take a while. The loaded details
+// open the detail pane, or update the open one when refresh is set.
+func (m *tableModel) loadDetail(pid int, withEnv, refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		details, err := m.pm.ProcessDetails(ctx, pid, withEnv)
+
+		return detailLoadedMsg{details: details, refresh: refresh, err: err}
+	}
+}
+
+// showLoadedDetail shows the details loaded by loadDetail.
+func (m *tableModel) showLoadedDetail(msg detailLoadedMsg) {
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Details failed: %v", msg.err), statusKindError)
+		return
+	}
+
+	if msg.refresh {
+		// The pane may have been closed or moved to another process meanwhile.
+		if m.showDetail && m.detail.details.PID == msg.details.PID {
+			m.detail.SetDetails(msg.details)
+		}
+		return
+	}
+
+	m.detail = newDetailModel(msg.details, m.tableViewWidth)
+	m.showDetail = true
+}
+
+// applyPreset replaces the search query and the filter toggles with the preset ones.
+func (m *tableModel) applyPreset(preset SearchPreset) error {
+	filters, err := preset.filterState()
+	if err != nil {
+		return err
+	}
+
+	mode, err := parseSearchMode(preset.Mode)
+	if err != nil {
+		return err
+	}
+
+	m.filters = filters
+	m.searchInput.SetMode(mode)
+	m.searchInput.SetValue(preset.Query)
+	m.setSearchQuery(preset.Query)
+
+	return m.searchInput.err
+}
+
+// saveStore persists the search history and presets reporting failures in the status bar.
+// A non-empty message is shown on success.
+func (m *tableModel) saveStore(message string) {
+	if err := m.store.Save(); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Save search history failed: %v", err), statusKindError)
+		return
+	}
+
+	if message != "" {
+		m.setStatusMessage(message, statusKindInfo)
+	}
+}
+
+// saveSnapshot saves the current state to a file in the working directory.
+func (m *tableModel) saveSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	snapshot, err := m.pm.Snapshot(ctx)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Save failed: %v", err), statusKindError)
+		return
+	}
+
+	path := defaultSnapshotPath(snapshot)
+	if err := SaveSnapshot(path, snapshot); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Save failed: %v", err), statusKindError)
+		return
+	}
+
+	m.setStatusMessage("Snapshot saved to "+path, statusKindInfo)
+}
+
+// suggestFreePorts looks up the free ports nearest to the port in the background,
+// the bind probes may take a while.
+func (m *tableModel) suggestFreePorts(port int, protocol string) tea.Cmd {
+	query := FreePortQuery{Protocol: "tcp", Near: port, Count: 5}
+	if strings.HasPrefix(strings.ToUpper(protocol), "UDP") {
+		query.Protocol = "udp"
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		ports, err := m.pm.FreePorts(ctx, query)
+
+		return freePortsMsg{protocol: query.Protocol, port: port, ports: ports, err: err}
+	}
+}
+
+// showFreePorts reports the free ports found by suggestFreePorts in the status bar.
+func (m *tableModel) showFreePorts(msg freePortsMsg) {
+	timedOut := errors.Is(msg.err, context.DeadlineExceeded)
+	if msg.err != nil && (!timedOut || len(msg.ports) == 0) {
+		m.setStatusMessage(fmt.Sprintf("Free port lookup failed: %v", msg.err), statusKindError)
+		return
+	}
+
+	suggestions := make([]string, len(msg.ports))
+	for i, p := range msg.ports {
+		suggestions[i] = strconv.Itoa(p)
+	}
+
+	status := fmt.Sprintf("Free %s ports near %d: %s", msg.protocol, msg.port, strings.Join(suggestions, ", "))
+	if timedOut {
+		status += " (lookup timed out)"
+	}
+
+	m.setStatusMessage(status, statusKindInfo)
+}
+
 func (m *tableModel) View() string {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
 	defer cancel()
@@ -452,38 +1014,52 @@ func (m *tableModel) View() string {
 	m.allProcesses = processes
 
 	// Filter processes based on search query
-	filteredProcesses := m.filterProcesses(processes)
-	m.filteredRowCount = len(filteredProcesses)
+	results := m.filterProcesses(processes)
+	if m.sortColumn != "" {
+		slices.SortStableFunc(results, func(a, b searchResult) int {
+			if m.sortDesc {
+				return compareColumn(m.sortColumn, b.process, a.process)
+			}
+			return compareColumn(m.sortColumn, a.process, b.process)
+		})
+	}
+	m.visibleProcesses = make([]Process, len(results))
+	m.filteredRowCount = len(results)
+	m.updateHistories()
 
-	rows := make([]table.Row, 0, len(filteredProcesses))
+	rows := make([]table.Row, 0, len(results))
 
-	// Get process column width for scrolling
 	cols := m.table.Columns()
-	processColWidth := 15 // default
-	if len(cols) > 5 {
-		processColWidth = cols[5].Width
-	}
-
-	for _, process := range filteredProcesses {
-		// Apply horizontal scroll to process name
-		processName := scrollText(process.Name, m.horizontalScroll, processColWidth)
-
-		rows = append(rows, table.Row{
-			strconv.Itoa(process.PID),
-			process.Protocol,
-			strconv.Itoa(process.Port),
-			process.Status,
-			process.LocalAddr,
-			processName,
-		})
+	m.columnOverflow = make(map[string]int, len(m.columns))
+	m.maxHorizontalScroll = 0
+
+	for i, result := range results {
+		m.visibleProcesses[i] = result.process
+
+		row := make(table.Row, len(m.columns))
+		for j, column := range m.columns {
+			row[j] = m.renderCell(column, result)
+			if overflow := ansi.StringWidth(row[j]) - cols[j].Width; overflow > m.columnOverflow[column] {
+				m.columnOverflow[column] = overflow
+				m.maxHorizontalScroll = max(m.maxHorizontalScroll, overflow)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	// The rows may have shrunk since the last scroll.
+	m.horizontalScroll = min(m.horizontalScroll, m.maxHorizontalScroll)
+	for _, row := range rows {
+		for j := range row {
+			row[j] = scrollCell(row[j], m.horizontalScroll, cols[j].Width)
+		}
 	}
 
 	m.table.SetRows(rows)
 
 	// Build the main view
-	rawTableView := m.table.View()
 	frameWidth := baseStyle.GetHorizontalFrameSize()
-	tableBodyWidth := lipgloss.Width(rawTableView)
+	tableBodyWidth := lipgloss.Width(m.table.headersView())
 
 	tableWidth := m.tableViewWidth
 	if tableWidth == 0 {
@@ -498,8 +1074,6 @@ func (m *tableModel) View() string {
 		tableWidth = m.width
 	}
 
-	tableView := baseStyle.Width(tableWidth).Render(rawTableView)
-
 	versionLabel := Version
 	if versionLabel == "" {
 		versionLabel = "dev"
@@ -510,8 +1084,16 @@ func (m *tableModel) View() string {
 		}
 	}
 
-	shortcuts := "[/] Search  [t] TCP  [u] UDP  [l] LISTEN  [e] EST  [k] Kill"
-	left := headerLeftStyle.Render(fmt.Sprintf("%s %s", appName, versionLabel))
+	shortcuts := joinShortcuts("  ",
+		m.keys.Search, m.keys.TCP, m.keys.UDP, m.keys.Listen, m.keys.Established,
+		m.keys.Exposed, m.keys.Kill, m.keys.Free, m.keys.Presets, m.keys.Columns, m.keys.Summary,
+		m.keys.History,
+	)
+	title := fmt.Sprintf("%s %s", appName, versionLabel)
+	if origin := m.pm.Origin(); origin != nil {
+		title += fmt.Sprintf("  [snapshot %s @ %s]", origin.Host, origin.CreatedAt.Format(time.DateTime))
+	}
+	left := headerLeftStyle.Render(title)
 	leftWidth := lipgloss.Width(left)
 	rightSpace := tableWidth - leftWidth
 	if rightSpace < 0 {
@@ -524,50 +1106,199 @@ func (m *tableModel) View() string {
 	headerView = lipgloss.NewStyle().MaxWidth(tableWidth).Render(headerView)
 
 	sections := []string{headerView}
+	if m.showSummary {
+		sections = append(sections, renderSummary(Summarize(processes, summaryTopProcesses), tableWidth))
+	}
 	if m.showSearch {
 		m.searchInput.SetWidth(tableWidth)
 		sections = append(sections, m.searchInput.View())
 	}
 
+	// Small terminals drop the empty first line and the vertical padding of the table.
+	compact := m.height > 0 && m.height < compactHeight
+	tableStyle := baseStyle
+	topMargin := 1
+	if compact {
+		tableStyle = baseStyle.PaddingTop(0).PaddingBottom(0)
+		topMargin = 0
+	}
+
+	tableTop := topMargin + lipgloss.Height(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	m.layout = screenLayout{
+		tableX: tableStyle.GetBorderLeftSize() + tableStyle.GetPaddingLeft(),
+		tableY: tableTop + tableStyle.GetBorderTopSize() + tableStyle.GetPaddingTop(),
+	}
+
+	if m.showDetail {
+		m.detail.SetWidth(tableWidth)
+	}
+
+	// The table fills the lines left by the other sections and the status bar.
+	if m.height > 0 {
+		available := m.height - tableTop - tableStyle.GetVerticalFrameSize() - statusBarHeight
+		if m.showDetail {
+			m.detail.SetHeight(available / 2)
+			available -= lipgloss.Height(m.detail.View())
+		}
+		m.table.SetHeight(max(available, minTableHeight))
+	}
+
+	tableView := tableStyle.Width(tableWidth).Render(m.table.View())
+
 	tableContent := tableView
-	if m.confirmKill {
-		tableContent = overlayConfirmBox(tableWidth, tableView, m.confirmTarget)
+	switch {
+	case m.confirmKill:
+		tableContent = overlayConfirmBox(tableWidth, tableView, m.confirmTarget, m.confirmRisk, m.riskConfirmed, m.typedConfirmation)
+		m.layout.killButton = findLabel(tableContent, tableTop, confirmKillLabel)
+		m.layout.cancelButton = findLabel(tableContent, tableTop, confirmCancelLabel)
+	case m.showPresets:
+		tableContent = overlayBox(tableWidth, tableView, m.presetPicker.View())
+	case m.showColumns:
+		m.columnChooser.SetHeight(lipgloss.Height(tableView))
+		tableContent = overlayBox(tableWidth, tableView, m.columnChooser.View())
+	case m.showHistory:
+		tableContent = overlayBox(tableWidth, tableView, m.history.View())
 	}
 	sections = append(sections, tableContent)
 
+	if m.showDetail {
+		m.layout.detailY = tableTop + lipgloss.Height(tableContent)
+		sections = append(sections, m.detail.View())
+	}
+
 	mainView := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	// Add status bar
 	statusBar := m.renderStatusBar()
-	inner := lipgloss.JoinVertical(lipgloss.Left, mainView, statusBar)
-	mainView = lipgloss.JoinVertical(lipgloss.Left, "", inner)
+	if m.width > 0 {
+		statusBar = lipgloss.NewStyle().MaxWidth(m.width).Render(statusBar)
+	}
+	mainView = lipgloss.JoinVertical(lipgloss.Left, mainView, statusBar)
+	if topMargin > 0 {
+		mainView = lipgloss.JoinVertical(lipgloss.Left, "", mainView)
+	}
 
 	return mainView
 }
 
-func (m *tableModel) filterProcesses(processes []Process) []Process {
-	tokens := m.searchTokens()
+// renderCell renders the value of the column for the search result.
+// The value is not cut to the column width.
+func (m *tableModel) renderCell(column string, result searchResult) string {
+	process := result.process
+
+	switch column {
+	case "address":
+		return renderExposure(process.LocalAddr, process.Exposure)
+	case "process":
+		return highlightText(process.Name, result.highlights)
+	case "traffic":
+		return sparkline(m.traffic[newRowSocket(process)], tableColumns[column].min)
+	case "conns":
+		return sparkline(sampleValues(m.samples[process.PID], sampleSockets), tableColumns[column].min)
+	case "cpu_history":
+		return sparkline(sampleValues(m.samples[process.PID], sampleCPU), tableColumns[column].min)
+	case "memory_history":
+		return sparkline(sampleValues(m.samples[process.PID], sampleMemory), tableColumns[column].min)
+	case "status", "recvq":
+		if process.BacklogFull() {
+			return failureStyle.Render(columnValue(column, process))
+		}
+		return columnValue(column, process)
+	default:
+		return columnValue(column, process)
+	}
+}
+
+// rowSocket identifies the socket of a row by its addresses as shown,
+// so the rows are matched to the cached traffic without parsing them.
+type rowSocket struct {
+	protocol string
+	local    string
+	remote   string
+}
+
+func newRowSocket(p Process) rowSocket {
+	return rowSocket{protocol: p.Protocol, local: p.LocalAddr, remote: p.RemoteAddr}
+}
+
+// updateHistories takes the data of the shown sparkline columns once per refresh.
+func (m *tableModel) updateHistories() {
+	refreshed := m.pm.Refreshed()
+	stale := !refreshed.Equal(m.historyRefreshed)
+	m.historyRefreshed = refreshed
+
+	switch {
+	case !slices.Contains(m.columns, "traffic"):
+		m.traffic = nil
+	case m.traffic == nil || stale:
+		histories := m.pm.BandwidthHistories()
+		m.traffic = make(map[rowSocket][]float64, len(m.allProcesses))
+		for _, process := range m.allProcesses {
+			if key, ok := processSocketKey(process); ok {
+				m.traffic[newRowSocket(process)] = histories[key]
+			}
+		}
+	}
+
+	switch {
+	case !slices.ContainsFunc(m.columns, func(column string) bool {
+		return column == "conns" || column == "cpu_history" || column == "memory_history"
+	}):
+		m.samples = nil
+	case m.samples == nil || stale:
+		m.samples = m.pm.ProcessHistories()
+	}
+}
+
+// expandNextColumn gives the spare width to the next cut off column,
+// restoring the column widths after the last one.
+func (m *tableModel) expandNextColumn() {
+	start := slices.Index(m.columns, m.expandedColumn) + 1
+
+	m.expandedColumn = ""
+	for _, column := range m.columns[start:] {
+		if m.columnOverflow[column] > 0 {
+			m.expandedColumn = column
+			break
+		}
+	}
+	m.updateTableSize()
+
+	switch {
+	case m.expandedColumn != "":
+		m.setStatusMessage("Expanded "+tableColumns[m.expandedColumn].title+" column", statusKindInfo)
+	case start > 0:
+		m.setStatusMessage("Column widths restored", statusKindInfo)
+	default:
+		m.setStatusMessage("No column is cut off", statusKindInfo)
+	}
+}
+
+func (m *tableModel) filterProcesses(processes []Process) []searchResult {
 	filtered := make([]Process, 0, len(processes))
 
 	for _, process := range processes {
 		if !m.filters.allows(process) {
 			continue
 		}
-		if !matchesTokens(process, tokens) {
-			continue
-		}
 		filtered = append(filtered, process)
 	}
 
-	return filtered
+	return m.query.filter(filtered)
 }
 
-func (m *tableModel) searchTokens() []string {
-	query := strings.ToLower(strings.TrimSpace(m.searchQuery))
-	if query == "" {
-		return nil
+// setSearchQuery parses the query and applies it to the table.
+// An invalid query is reported in the search box and the last valid query stays applied.
+func (m *tableModel) setSearchQuery(input string) {
+	m.searchQuery = input
+
+	query, err := newSearchQuery(input, m.searchInput.Mode())
+	m.searchInput.SetError(err)
+	if err != nil {
+		return
 	}
-	return strings.Fields(query)
+
+	m.query = query
 }
 
 func matchesTokens(process Process, tokens []string) bool {
@@ -580,8 +1311,10 @@ func matchesTokens(process Process, tokens []string) bool {
 		strconv.Itoa(process.PID),
 		strings.ToLower(process.Protocol),
 		strconv.Itoa(process.Port),
+		strings.ToLower(process.Service),
 		strings.ToLower(process.Status),
 		strings.ToLower(process.LocalAddr),
+		process.Exposure,
 	}
 
 	for _, token := range tokens {
@@ -600,68 +1333,104 @@ func matchesTokens(process Process, tokens []string) bool {
 	return true
 }
 
-// scrollText applies horizontal scrolling to text, showing a window of maxWidth characters
-// starting at offset position. Always returns exactly maxWidth characters to prevent wrapping.
-func scrollText(text string, offset int, maxWidth int) string {
-	if maxWidth <= 0 {
-		return ""
+// scrollCell returns the window of the value which fits the width starting at
+// the offset in display cells. The offset is limited so the end of the value
+// stays visible and cut off text is marked with an ellipsis on either side.
+func scrollCell(value string, offset int, width int) string {
+	total := ansi.StringWidth(value)
+	if width <= 0 || total <= width {
+		return value
 	}
 
-	// If text fits, pad it to maxWidth
-	if len(text) <= maxWidth {
-		if len(text) < maxWidth {
-			return text + strings.Repeat(" ", maxWidth-len(text))
+	offset = clamp(offset, 0, total-width)
+	window := ansi.Cut(value, offset, offset+width)
+
+	if offset > 0 {
+		// TruncateLeft keeps a wide character it cuts through, so cut until the
+		// ellipsis fits.
+		rest := ansi.TruncateLeft(window, 1, "")
+		for cut := 2; ansi.StringWidth(rest) > width-1; cut++ {
+			rest = ansi.TruncateLeft(window, cut, "")
 		}
+		window = "…" + rest
+	}
+	if offset+width < total {
+		window = ansi.Truncate(window, width-1, "") + "…"
+	}
+
+	return window
+}
+
+// highlightText renders the text with the characters at the given byte offsets highlighted.
+func highlightText(text string, highlights []int) string {
+	if len(highlights) == 0 {
 		return text
 	}
 
-	// Apply offset
-	if offset >= len(text) {
-		offset = len(text) - maxWidth
-		if offset < 0 {
-			offset = 0
-		}
+	highlighted := make(map[int]bool, len(highlights))
+	for _, index := range highlights {
+		highlighted[index] = true
 	}
 
-	end := offset + maxWidth
-	if end > len(text) {
-		// If we're at the end, show the last maxWidth characters
-		result := text[len(text)-maxWidth:]
-		return result
+	var b strings.Builder
+	for i, r := range text {
+		if highlighted[i] {
+			b.WriteString(searchHighlightStyle.Render(string(r)))
+			continue
+		}
+		b.WriteRune(r)
 	}
 
-	return text[offset:end]
+	return b.String()
 }
 
 func (m *tableModel) renderStatusBar() string {
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
+		Foreground(lipgloss.Color(activeTheme.Muted)).
 		Padding(0, 1)
 
 	if m.confirmKill {
 		prompt := fmt.Sprintf("Kill %s (%d)? [y/N]", displayName(m.confirmTarget.Name), m.confirmTarget.PID)
+		if m.riskConfirmed {
+			prompt = fmt.Sprintf("%s. Type %s and press Enter to kill it anyway, Esc to cancel: %s",
+				m.confirmRisk.Reason, confirmationWord(m.confirmTarget), m.typedConfirmation)
+		}
 		return statusStyle.Render(prompt)
 	}
 
 	if m.statusMessage != "" && time.Now().Before(m.statusExpires) {
 		style := statusStyle
 		if m.statusKind == statusKindError {
-			style = style.Foreground(lipgloss.Color("203"))
+			style = style.Foreground(lipgloss.Color(activeTheme.Error))
 		} else {
-			style = style.Foreground(lipgloss.Color("205"))
+			style = style.Foreground(lipgloss.Color(activeTheme.Accent))
 		}
 
 		return style.Render(m.statusMessage)
 	}
 
-	status := "[q] Quit :: [x] Clear :: [Shift+←/→] Scroll"
+	if m.pendingCopy {
+		return statusStyle.Render(copyTargetHelp)
+	}
+
+	if m.showSearch {
+		return statusStyle.Render("[Enter] Apply :: [Esc] Cancel :: [Ctrl+R] Regex :: [Ctrl+T] Fuzzy")
+	}
+
+	status := joinShortcuts(" :: ", m.keys.Quit, m.keys.Clear, m.keys.Save, m.keys.Copy)
+	if scroll := scrollShortcut(m.keys.ScrollLeft, m.keys.ScrollRight); scroll != "" {
+		status += " :: " + scroll
+	}
+	if expand := shortcut(m.keys.Expand); expand != "" {
+		status += " :: " + expand
+	}
 	if labels := m.filters.activeLabels(); len(labels) > 0 {
 		status += "  |  " + strings.Join(labels, ", ")
 	}
 
 	// Add horizontal scroll position indicator
 	if m.horizontalScroll > 0 {
-		status += fmt.Sprintf("  |  ←→ (%d)", m.horizontalScroll)
+		status += fmt.Sprintf("  |  ←→ (%d/%d)", m.horizontalScroll, m.maxHorizontalScroll)
 	}
 
 	// Add scroll indicators if there are more rows than visible
@@ -688,13 +1457,37 @@ func (m *tableModel) renderStatusBar() string {
 	return statusStyle.Render(status)
 }
 
-func overlayConfirmBox(width int, tableView string, target Process) string {
-	box := renderConfirmBox(target)
+func overlayConfirmBox(width int, tableView string, target Process, risk KillRisk, riskConfirmed bool, typed string) string {
+	return overlayBox(width, tableView, renderConfirmBox(target, risk, riskConfirmed, typed))
+}
+
+// overlayBox draws the box centered over the dimmed table view.
+// The view grows when the box is taller than the table.
+func overlayBox(width int, tableView string, box string) string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(activeTheme.Muted))
 
-	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	background := dim.Render(tableView)
+	background := strings.Split(ansi.Strip(tableView), "\n")
+	boxLines := strings.Split(box, "\n")
+	boxWidth := lipgloss.Width(box)
+
+	left := max((width-boxWidth)/2, 0)
+	top := max((len(background)-len(boxLines))/2, 0)
+	for len(background) < top+len(boxLines) {
+		background = append(background, "")
+	}
 
-	overlay := lipgloss.PlaceHorizontal(width, lipgloss.Center, box)
+	lines := make([]string, len(background))
+	for i, line := range background {
+		if i < top || i >= top+len(boxLines) {
+			lines[i] = dim.Render(line)
+			continue
+		}
+
+		before := ansi.Truncate(line, left, "")
+		before += strings.Repeat(" ", left-ansi.StringWidth(before))
+		after := ansi.TruncateLeft(line, left+boxWidth, "")
+		lines[i] = dim.Render(before) + boxLines[i-top] + dim.Render(after)
+	}
 
-	return lipgloss.JoinVertical(lipgloss.Center, overlay, background)
+	return strings.Join(lines, "\n")
 }
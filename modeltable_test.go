@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestScrollCell(t *testing.T) {
+	tests := map[string]struct {
+		value  string
+		offset int
+		width  int
+		want   string
+	}{
+		"fits":                {value: "nginx", offset: 3, width: 10, want: "nginx"},
+		"no width":            {value: "nginx", width: 0, want: "nginx"},
+		"start":               {value: "abcdefghij", offset: 0, width: 5, want: "abcd…"},
+		"negative offset":     {value: "abcdefghij", offset: -3, width: 5, want: "abcd…"},
+		"middle":              {value: "abcdefghij", offset: 2, width: 5, want: "…def…"},
+		"end":                 {value: "abcdefghij", offset: 5, width: 5, want: "…ghij"},
+		"offset past the end": {value: "abcdefghij", offset: 99, width: 5, want: "…ghij"},
+		"wide characters":     {value: "日本語テキスト", offset: 0, width: 6, want: "日本…"},
+		"wide characters end": {value: "日本語テキスト", offset: 8, width: 6, want: "…スト"},
+		"wide character cut":  {value: "日本語テキスト", offset: 3, width: 6, want: "…語テ…"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := scrollCell(tc.value, tc.offset, tc.width); got != tc.want {
+				t.Errorf("scrollCell(%q, %d, %d) = %q, want %q", tc.value, tc.offset, tc.width, got, tc.want)
+			}
+		})
+	}
+}
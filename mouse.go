@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wheelRows is the number of rows the mouse wheel scrolls the table by.
+const wheelRows = 3
+
+// screenZone is a clickable area of a single screen line.
+type screenZone struct {
+	x, y, width int
+}
+
+func (z screenZone) contains(x, y int) bool {
+	return y == z.y && x >= z.x && x < z.x+z.width
+}
+
+// screenLayout records where the last View drew the elements the mouse can act on.
+type screenLayout struct {
+	// tableX and tableY are the screen position of the table header inside the border.
+	tableX, tableY int
+	// detailY is the first line of the detail pane when it is shown.
+	detailY int
+	// killButton and cancelButton are the buttons of the kill confirm dialog when it is shown.
+	killButton, cancelButton screenZone
+}
+
+// findLabel returns the zone of the first occurrence of the label in the view
+// drawn starting at the top line, or an empty zone if the label is not drawn.
+func findLabel(view string, top int, label string) screenZone {
+	for i, line := range strings.Split(view, "\n") {
+		line = ansi.Strip(line)
+		if index := strings.Index(line, label); index >= 0 {
+			return screenZone{x: ansi.StringWidth(line[:index]), y: top + i, width: ansi.StringWidth(label)}
+		}
+	}
+
+	return screenZone{}
+}
+
+// handleMouse selects rows on click, sorts by the clicked header, scrolls on wheel
+// and presses the kill confirm dialog buttons.
+func (m *tableModel) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	if m.confirmKill {
+		if msg.Button != tea.MouseButtonLeft {
+			return m, nil
+		}
+		switch {
+		case m.layout.killButton.contains(msg.X, msg.Y):
+			m.killConfirmed()
+		case m.layout.cancelButton.contains(msg.X, msg.Y):
+			m.cancelKill()
+		}
+		return m, nil
+	}
+
+	if m.showPresets || m.showColumns || m.showHistory {
+		return m, nil
+	}
+
+	if m.showDetail && msg.Y >= m.layout.detailY {
+		_, cmd := m.detail.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.table.MoveUp(wheelRows)
+	case tea.MouseButtonWheelDown:
+		m.table.MoveDown(wheelRows)
+	case tea.MouseButtonLeft:
+		x, y := msg.X-m.layout.tableX, msg.Y-m.layout.tableY
+		if y == 0 {
+			if column := m.table.ColumnAt(x); column >= 0 {
+				m.toggleSort(m.columns[column])
+			}
+			return m, nil
+		}
+
+		row := m.table.RowAt(y)
+		if row < 0 || m.table.ColumnAt(x) < 0 || row == m.table.Cursor() {
+			return m, nil
+		}
+		m.table.SetCursor(row)
+		if m.showDetail {
+			return m, m.loadDetail(m.visibleProcesses[row].PID, false, false)
+		}
+	}
+
+	return m, nil
+}
+
+// toggleSort sorts the table by the column: ascending first, then descending,
+// then back to the default order.
+func (m *tableModel) toggleSort(column string) {
+	switch {
+	case m.sortColumn != column:
+		m.sortColumn, m.sortDesc = column, false
+	case !m.sortDesc:
+		m.sortDesc = true
+	default:
+		m.sortColumn, m.sortDesc = "", false
+	}
+
+	m.setColumns(m.columns)
+	m.table.GotoTop()
+}
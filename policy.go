@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ErrPolicyViolation indicates that the listening surface doesn't match the policy.
+	ErrPolicyViolation = Error("policy violation")
+)
+
+// Violation kinds reported by Policy.Check.
+const (
+	// ViolationUnexpected is a listener that is not described by the policy.
+	ViolationUnexpected = "unexpected"
+	// ViolationMissing is an expected listener that is not present.
+	ViolationMissing = "missing"
+	// ViolationWrongAddress is a listener bound to a different address than required.
+	ViolationWrongAddress = "wrong_address"
+	// ViolationWrongProcess is a listener owned by a different process than expected.
+	ViolationWrongProcess = "wrong_process"
+)
+
+// Policy describes the allowed listening surface of a host.
+type Policy struct {
+	Listeners []PolicyListener `yaml:"listeners"`
+}
+
+// PolicyListener describes an allowed listener.
+// Empty Process and Address (or "*") match anything.
+type PolicyListener struct {
+	Port     int    `yaml:"port" json:"port"`
+	Protocol string `yaml:"protocol" json:"protocol"`
+	Process  string `yaml:"process" json:"process,omitempty"`
+	Address  string `yaml:"address" json:"address,omitempty"`
+	// Optional listeners are allowed but not required to be present.
+	Optional bool `yaml:"optional" json:"optional,omitempty"`
+}
+
+// String returns a short human-readable description of the listener.
+func (l PolicyListener) String() string {
+	var b strings.Builder
+	b.WriteString(l.Protocol + "/" + strconv.Itoa(l.Port))
+	if l.Address != "" && l.Address != "*" {
+		b.WriteString(" on " + l.Address)
+	}
+	if l.Process != "" {
+		b.WriteString(" by " + l.Process)
+	}
+	return b.String()
+}
+
+// Violation represents a single mismatch between the policy and the host.
+type Violation struct {
+	Kind     string          `json:"kind"`
+	Expected *PolicyListener `json:"expected,omitempty"`
+	Actual   *Process        `json:"actual,omitempty"`
+}
+
+// Message returns a human-readable description of the violation.
+func (v Violation) Message() string {
+	switch v.Kind {
+	case ViolationUnexpected:
+		return fmt.Sprintf("unexpected listener %s/%d on %s by %s (%d)",
+			strings.ToLower(v.Actual.Protocol), v.Actual.Port, v.Actual.LocalAddr, displayName(v.Actual.Name), v.Actual.PID)
+	case ViolationMissing:
+		return fmt.Sprintf("missing listener %s", v.Expected)
+	case ViolationWrongAddress:
+		return fmt.Sprintf("listener %s/%d is bound to %s, expected %s",
+			strings.ToLower(v.Actual.Protocol), v.Actual.Port, listenerHost(v.Actual.LocalAddr), v.Expected.Address)
+	case ViolationWrongProcess:
+		return fmt.Sprintf("listener %s/%d is owned by %s (%d), expected %s",
+			strings.ToLower(v.Actual.Protocol), v.Actual.Port, displayName(v.Actual.Name), v.Actual.PID, v.Expected.Process)
+	default:
+		return v.Kind
+	}
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (Policy, error) {
+	var policy Policy
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, fmt.Errorf("read policy: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+
+	for i := range policy.Listeners {
+		listener := &policy.Listeners[i]
+
+		if listener.Port < 1 || listener.Port > 65535 {
+			return policy, fmt.Errorf("policy listener %d: invalid port: %d", i+1, listener.Port)
+		}
+
+		listener.Protocol = strings.ToLower(listener.Protocol)
+		switch listener.Protocol {
+		case "":
+			listener.Protocol = "tcp"
+		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		default:
+			return policy, fmt.Errorf("policy listener %d: invalid protocol: %s", i+1, listener.Protocol)
+		}
+	}
+
+	return policy, nil
+}
+
+// Check compares the processes against the policy and returns found violations.
+// TCP sockets in the LISTEN state and unconnected UDP sockets are treated as listeners.
+func (p Policy) Check(processes []Process) []Violation {
+	violations := make([]Violation, 0)
+	present := make([]bool, len(p.Listeners))
+
+	for _, process := range processes {
+		if !isListener(process) {
+			continue
+		}
+
+		actual := process
+		matched := false
+		wrongAddress, wrongProcess := -1, -1
+
+		for i, expected := range p.Listeners {
+			if expected.Port != process.Port || !protocolMatches(expected.Protocol, process.Protocol) {
+				continue
+			}
+
+			processOK := expected.Process == "" || strings.EqualFold(expected.Process, process.Name)
+			addressOK := addressMatches(expected.Address, listenerHost(process.LocalAddr))
+
+			switch {
+			case processOK && addressOK:
+				present[i] = true
+				matched = true
+			case processOK:
+				wrongAddress = i
+			default:
+				wrongProcess = i
+			}
+		}
+
+		switch {
+		case matched:
+		case wrongAddress >= 0:
+			present[wrongAddress] = true
+			violations = append(violations, Violation{Kind: ViolationWrongAddress, Expected: &p.Listeners[wrongAddress], Actual: &actual})
+		case wrongProcess >= 0:
+			present[wrongProcess] = true
+			violations = append(violations, Violation{Kind: ViolationWrongProcess, Expected: &p.Listeners[wrongProcess], Actual: &actual})
+		default:
+			violations = append(violations, Violation{Kind: ViolationUnexpected, Actual: &actual})
+		}
+	}
+
+	for i := range p.Listeners {
+		if present[i] || p.Listeners[i].Optional {
+			continue
+		}
+		violations = append(violations, Violation{Kind: ViolationMissing, Expected: &p.Listeners[i]})
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violationPort(violations[i]) < violationPort(violations[j])
+	})
+
+	return violations
+}
+
+func violationPort(v Violation) int {
+	if v.Actual != nil {
+		return v.Actual.Port
+	}
+	return v.Expected.Port
+}
+
+// isListener reports whether the socket accepts new peers: a listening TCP socket
+// or an unconnected UDP socket. A connected UDP socket only talks to its remote address.
+func isListener(process Process) bool {
+	protocol := strings.ToUpper(process.Protocol)
+	if strings.HasPrefix(protocol, "UDP") {
+		return process.RemoteAddr == ""
+	}
+	return process.Status == StatusListen
+}
+
+// protocolMatches reports whether the policy protocol matches the process protocol.
+// "tcp" and "udp" match both address families, "tcp4"/"tcp6" and "udp4"/"udp6" only one.
+func protocolMatches(expected, actual string) bool {
+	actual = strings.ToLower(actual)
+
+	switch expected {
+	case "tcp", "udp":
+		return strings.HasPrefix(actual, expected)
+	case "tcp4":
+		return actual == "tcp"
+	case "udp4":
+		return actual == "udp"
+	default:
+		return actual == expected
+	}
+}
+
+// addressMatches reports whether the host satisfies the expected bind address.
+func addressMatches(expected, host string) bool {
+	if expected == "" || expected == "*" {
+		return true
+	}
+	return expected == host
+}
+
+// listenerHost returns the host part of a "host:port" local address.
+func listenerHost(addr string) string {
+	if addrPort, err := netip.ParseAddrPort(addr); err == nil {
+		return addrPort.Addr().String()
+	}
+
+	i := strings.LastIndex(addr, ":")
+	if i < 0 {
+		return addr
+	}
+	return addr[:i]
+}
+
+// CheckReport is the result of a policy check.
+type CheckReport struct {
+	Policy     string      `json:"policy"`
+	Host       string      `json:"host"`
+	Passed     bool        `json:"passed"`
+	Listeners  int         `json:"listeners"`
+	Violations []Violation `json:"violations"`
+}
+
+// RenderCheckReportText renders the report for humans.
+func RenderCheckReportText(report CheckReport) string {
+	var b strings.Builder
+
+	if report.Passed {
+		fmt.Fprintf(&b, "%s %d listeners match policy %s\n", successStyle.Render("OK:"), report.Listeners, report.Policy)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%s %d violations of policy %s\n", failureStyle.Render("FAIL:"), len(report.Violations), report.Policy)
+	for _, violation := range report.Violations {
+		fmt.Fprintf(&b, "  - %s\n", violation.Message())
+	}
+
+	return b.String()
+}
+
+// RenderCheckReportJSON renders the report as indented JSON.
+func RenderCheckReportJSON(report CheckReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal report: %w", err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// RenderCheckReportJUnit renders the report as JUnit XML.
+// Every policy listener becomes a test case, unexpected listeners become failed test cases.
+func RenderCheckReportJUnit(report CheckReport, policy Policy) (string, error) {
+	suite := junitTestSuite{Name: cmdName + " check " + report.Host}
+
+	failures := make(map[*PolicyListener]Violation, len(report.Violations))
+	for _, violation := range report.Violations {
+		if violation.Expected != nil {
+			failures[violation.Expected] = violation
+		}
+	}
+
+	for i := range policy.Listeners {
+		listener := &policy.Listeners[i]
+		testCase := junitTestCase{Name: listener.String(), ClassName: report.Policy}
+
+		if violation, ok := failures[listener]; ok {
+			testCase.Failure = &junitFailure{Message: violation.Message(), Type: violation.Kind}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, violation := range report.Violations {
+		if violation.Kind != ViolationUnexpected {
+			continue
+		}
+
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s/%d on %s", strings.ToLower(violation.Actual.Protocol), violation.Actual.Port, violation.Actual.LocalAddr),
+			ClassName: report.Policy,
+			Failure:   &junitFailure{Message: violation.Message(), Type: violation.Kind},
+		})
+	}
+
+	suite.Tests = len(suite.Cases)
+	for _, testCase := range suite.Cases {
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal report: %w", err)
+	}
+
+	return xml.Header + string(data) + "\n", nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	nginx := Process{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:80"}
+	postgres := Process{PID: 20, Name: "postgres", Port: 5432, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "127.0.0.1:5432"}
+	dns := Process{PID: 30, Name: "dnsmasq", Port: 53, Protocol: ProtocolUDP, Status: StatusActive, LocalAddr: "127.0.0.1:53"}
+
+	tests := map[string]struct {
+		policy    Policy
+		processes []Process
+		want      []string
+	}{
+		"matching listeners": {
+			policy: Policy{Listeners: []PolicyListener{
+				{Port: 80, Protocol: "tcp", Process: "nginx", Address: "*"},
+				{Port: 5432, Protocol: "tcp", Address: "127.0.0.1"},
+				{Port: 53, Protocol: "udp"},
+			}},
+			processes: []Process{nginx, postgres, dns},
+			want:      []string{},
+		},
+		"process name is case-insensitive": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp", Process: "NGINX"}}},
+			processes: []Process{nginx},
+			want:      []string{},
+		},
+		"unexpected listener": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp"}}},
+			processes: []Process{nginx, postgres},
+			want:      []string{ViolationUnexpected},
+		},
+		"missing listener": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp"}, {Port: 443, Protocol: "tcp"}}},
+			processes: []Process{nginx},
+			want:      []string{ViolationMissing},
+		},
+		"optional listener may be missing": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp"}, {Port: 443, Protocol: "tcp", Optional: true}}},
+			processes: []Process{nginx},
+			want:      []string{},
+		},
+		"wrong address": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp", Address: "127.0.0.1"}}},
+			processes: []Process{nginx},
+			want:      []string{ViolationWrongAddress},
+		},
+		"wrong process": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp", Process: "caddy"}}},
+			processes: []Process{nginx},
+			want:      []string{ViolationWrongProcess},
+		},
+		"protocol must match": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 53, Protocol: "tcp"}}},
+			processes: []Process{dns},
+			want:      []string{ViolationUnexpected, ViolationMissing},
+		},
+		"tcp matches tcp6": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp"}}},
+			processes: []Process{{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP6, Status: StatusListen, LocalAddr: "[::]:80"}},
+			want:      []string{},
+		},
+		"tcp4 doesn't match tcp6": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 80, Protocol: "tcp4"}}},
+			processes: []Process{{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP6, Status: StatusListen, LocalAddr: "[::]:80"}},
+			want:      []string{ViolationUnexpected, ViolationMissing},
+		},
+		"established and connected sockets are not listeners": {
+			policy: Policy{},
+			processes: []Process{
+				{PID: 40, Name: "curl", Port: 50000, Protocol: ProtocolTCP, Status: "ESTABLISHED", LocalAddr: "10.0.0.2:50000", RemoteAddr: "10.0.0.1:80"},
+				{PID: 50, Name: "ntpd", Port: 40000, Protocol: ProtocolUDP, Status: StatusActive, LocalAddr: "10.0.0.2:40000", RemoteAddr: "10.0.0.1:123"},
+			},
+			want: []string{},
+		},
+		"violations are sorted by port": {
+			policy:    Policy{Listeners: []PolicyListener{{Port: 443, Protocol: "tcp"}}},
+			processes: []Process{postgres, nginx},
+			want:      []string{ViolationUnexpected, ViolationMissing, ViolationUnexpected},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			violations := tc.policy.Check(tc.processes)
+
+			got := make([]string, len(violations))
+			for i, violation := range violations {
+				got[i] = violation.Kind
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Check() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
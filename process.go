@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"strings"
 	"sync"
 	"time"
@@ -51,13 +52,51 @@ const (
 
 // Process represents a process that is using a port.
 type Process struct {
-	PID       int
-	Name      string
-	Port      int
-	Protocol  string
-	Status    string
-	LocalAddr string
-	Cmdline   string
+	PID        int     `json:"pid"`
+	Name       string  `json:"name"`
+	Port       int     `json:"port"`
+	Protocol   string  `json:"protocol"`
+	Status     string  `json:"status"`
+	LocalAddr  string  `json:"local_addr"`
+	RemoteAddr string  `json:"remote_addr,omitempty"`
+	User       string  `json:"user,omitempty"`
+	Cmdline    string  `json:"cmdline,omitempty"`
+	Service    string  `json:"service,omitempty"`
+	Exposure   string  `json:"exposure,omitempty"`
+	CPUPercent float64 `json:"cpu_percent,omitempty"`
+	MemoryRSS  uint64  `json:"memory_rss,omitempty"`
+	// RecvQ and SendQ are the bytes queued on the socket. For TCP listeners
+	// RecvQ is the accept queue length and SendQ the accept queue limit.
+	RecvQ         int     `json:"recv_q,omitempty"`
+	SendQ         int     `json:"send_q,omitempty"`
+	Retransmits   int     `json:"retransmits,omitempty"`
+	RTT           float64 `json:"rtt_ms,omitempty"`
+	BytesSent     uint64  `json:"bytes_sent,omitempty"`
+	BytesReceived uint64  `json:"bytes_received,omitempty"`
+	// RxRate and TxRate are the bytes per second received and sent when bandwidth
+	// sampling is enabled. Listeners include the traffic of their accepted connections.
+	RxRate float64 `json:"rx_rate,omitempty"`
+	TxRate float64 `json:"tx_rate,omitempty"`
+}
+
+// BacklogFull reports whether the accept queue of the TCP listener is full,
+// so new connections are dropped or delayed.
+func (p Process) BacklogFull() bool {
+	return p.Status == StatusListen && p.SendQ > 0 && p.RecvQ >= p.SendQ
+}
+
+// processInfo holds the details of a process shared by all its sockets.
+type processInfo struct {
+	name       string
+	user       string
+	cmdline    string
+	cpuPercent float64
+	memoryRSS  uint64
+}
+
+// AddrPort parses the local address of the process socket.
+func (p Process) AddrPort() (netip.AddrPort, error) {
+	return netip.ParseAddrPort(p.LocalAddr)
 }
 
 // Options represents the options for the GetOcupiedPorts function.
@@ -91,43 +130,98 @@ func WithFilterProtocol(protocol string) Option {
 	return func(o *Options) { o.FilterProtocol = protocol }
 }
 
+// ManagerOption represents an option for the NewProcessManager function.
+type ManagerOption func(*ProcessManager)
+
+// WithServiceRegistry returns an option that sets the registry used to resolve service names.
+func WithServiceRegistry(registry *ServiceRegistry) ManagerOption {
+	return func(m *ProcessManager) { m.services = registry }
+}
+
+// WithRefreshInterval returns an option that sets how often the process list is refreshed.
+func WithRefreshInterval(interval time.Duration) ManagerOption {
+	return func(m *ProcessManager) { m.interval = interval }
+}
+
 // ProcessManager is a manager for processes.
 type ProcessManager struct {
 	mu        sync.RWMutex
 	pidIndex  map[int]int
 	processes []Process
-	cancel    context.CancelFunc
-	ticker    *time.Ticker
-	err       error
+	// refreshed is the time processes were last fetched at.
+	refreshed time.Time
+	services  *ServiceRegistry
+	origin    *Snapshot
+	// bandwidth measures the socket throughput, nil unless bandwidth sampling is enabled.
+	bandwidth *bandwidthSampler
+	// history keeps the recent samples, nil unless the history is enabled.
+	history *processHistory
+	// refreshes counts the refreshes of the process list.
+	refreshes RefreshStats
+	// protection selects the processes KillProcess refuses or asks to confirm again.
+	protection KillProtection
+	// refreshMu serializes the refreshes and guards the handles, so the processes
+	// are collected without holding mu.
+	refreshMu sync.Mutex
+	// handles keeps the process handles between refreshes to measure CPU usage.
+	handles  map[int32]processHandle
+	interval time.Duration
+	cancel   context.CancelFunc
+	ticker   *time.Ticker
+	// err is the error of the last refresh, nil if it succeeded.
+	err error
 }
 
 // NewProcessManager creates a new ProcessManager.
-func NewProcessManager(ctx context.Context) (*ProcessManager, error) {
+func NewProcessManager(ctx context.Context, options ...ManagerOption) (*ProcessManager, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
 	manager := &ProcessManager{
-		pidIndex:  make(map[int]int),
-		processes: make([]Process, 0),
-		cancel:    cancel,
-		ticker:    time.NewTicker(time.Second * 5),
+		pidIndex:   make(map[int]int),
+		processes:  make([]Process, 0),
+		handles:    make(map[int32]processHandle),
+		interval:   defaultRefreshInterval,
+		protection: KillProtection{Processes: defaultProtectedProcesses},
+		cancel:     cancel,
 	}
 
-	// Fetch initial data immediately and wait for it to complete.
-	if err := manager.fetchProcesses(ctx, WithFilterProtocol("all")); err != nil {
-		manager.mu.Lock()
-		manager.err = fmt.Errorf("initial fetch processes: %w", err)
-		manager.mu.Unlock()
+	for _, option := range options {
+		option(manager)
 	}
 
+	manager.ticker = time.NewTicker(manager.interval)
+
+	// Fetch initial data immediately and wait for it to complete.
+	// The error is kept for Err, the TUI starts with an empty list on failure.
+	_ = manager.refresh(ctx)
+
 	// Start the background monitoring.
 	go manager.monitorProcesses(ctx)
 
 	return manager, nil
 }
 
+// NewProcessManagerFromSnapshot creates a read-only ProcessManager serving the saved snapshot.
+func NewProcessManagerFromSnapshot(snapshot Snapshot) *ProcessManager {
+	manager := &ProcessManager{
+		pidIndex:  make(map[int]int, len(snapshot.Processes)),
+		processes: snapshot.Processes,
+		origin:    &snapshot,
+		cancel:    func() {},
+	}
+
+	for i, process := range snapshot.Processes {
+		manager.pidIndex[process.PID] = i
+	}
+
+	return manager
+}
+
 func (m *ProcessManager) Stop() {
 	m.cancel()
-	m.ticker.Stop()
+	if m.ticker != nil {
+		m.ticker.Stop()
+	}
 }
 
 func (m *ProcessManager) Processes(ctx context.Context, options ...Option) ([]Process, error) {
@@ -142,7 +236,7 @@ func (m *ProcessManager) Processes(ctx context.Context, options ...Option) ([]Pr
 	filtered := make([]Process, 0, len(m.processes))
 
 	for _, process := range m.processes {
-		if listOptions.FilterProtocol != ProtocolAll && process.Protocol != listOptions.FilterProtocol {
+		if listOptions.FilterProtocol != ProtocolAll && !protocolMatches(listOptions.FilterProtocol, process.Protocol) {
 			continue
 		}
 
@@ -167,7 +261,30 @@ func (m *ProcessManager) Processes(ctx context.Context, options ...Option) ([]Pr
 	return filtered, nil
 }
 
-func (m *ProcessManager) KillProcess(ctx context.Context, pid int) error {
+// Refreshed returns the time the processes were last fetched at, zero for a snapshot.
+func (m *ProcessManager) Refreshed() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.refreshed
+}
+
+// KillProcess terminates the process and refreshes the process list. Processes with
+// a KillRisk are refused, or killed only when confirmed reports a second confirmation.
+func (m *ProcessManager) KillProcess(ctx context.Context, pid int, confirmed bool) error {
+	if m.origin != nil {
+		return ErrReadOnly
+	}
+
+	if risk, ok := m.KillRisk(ctx, pid); ok {
+		if risk.Refused {
+			return fmt.Errorf("%w: %s", ErrKillRefused, risk.Reason)
+		}
+		if !confirmed {
+			return fmt.Errorf("%w: %s", ErrKillNotConfirmed, risk.Reason)
+		}
+	}
+
 	proc, err := process.NewProcess(int32(pid))
 	if err != nil {
 		return fmt.Errorf("find process %d: %w", pid, err)
@@ -179,7 +296,7 @@ func (m *ProcessManager) KillProcess(ctx context.Context, pid int) error {
 		}
 	}
 
-	if err := m.fetchProcesses(ctx, WithFilterProtocol("all")); err != nil && !errors.Is(err, ErrNoConnectionsFound) {
+	if err := m.refresh(ctx); err != nil && !errors.Is(err, ErrNoConnectionsFound) {
 		return fmt.Errorf("refresh processes: %w", err)
 	}
 
@@ -193,16 +310,61 @@ func (m *ProcessManager) monitorProcesses(ctx context.Context) {
 			return
 
 		case <-m.ticker.C:
-			if err := m.fetchProcesses(ctx, WithFilterProtocol("all")); err != nil {
-				m.mu.Lock()
-				m.err = fmt.Errorf("fetch processes: %w", err)
-				m.mu.Unlock()
-				continue
-			}
+			// The error is kept for Err, the next tick tries again.
+			_ = m.refresh(ctx)
 		}
 	}
 }
 
+// RefreshStats counts the refreshes of the process list since the manager was created.
+type RefreshStats struct {
+	Count  int
+	Errors int
+	// Duration is the total time spent refreshing.
+	Duration    time.Duration
+	LastSuccess time.Time
+}
+
+// RefreshStats returns the refresh counters.
+func (m *ProcessManager) RefreshStats() RefreshStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := m.refreshes
+	stats.LastSuccess = m.refreshed
+
+	return stats
+}
+
+// Err returns the error of the last refresh of the process list, nil if it succeeded.
+// ErrNoConnectionsFound means there are no sockets to list.
+func (m *ProcessManager) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.err
+}
+
+// refresh fetches the sockets of all protocols, counts the refresh and keeps its error.
+func (m *ProcessManager) refresh(ctx context.Context) error {
+	start := time.Now()
+	err := m.fetchProcesses(ctx, WithFilterProtocol("all"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshes.Count++
+	m.refreshes.Duration += time.Since(start)
+	if err != nil {
+		m.err = fmt.Errorf("fetch processes: %w", err)
+		m.refreshes.Errors++
+	} else {
+		m.err = nil
+	}
+
+	return err
+}
+
 func (m *ProcessManager) fetchProcesses(ctx context.Context, options ...Option) error {
 	listOptions, err := parseOptions(options...)
 	if err != nil {
@@ -218,24 +380,14 @@ func (m *ProcessManager) fetchProcesses(ctx context.Context, options ...Option)
 		return ErrNoConnectionsFound
 	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if m.processes == nil {
-		m.processes = make([]Process, 0, len(connections))
-	}
-
-	if m.pidIndex == nil {
-		m.pidIndex = make(map[int]int, len(connections))
-	}
-
-	// Only clear if we have new data to replace it with.
-	if len(connections) > 0 {
-		clear(m.pidIndex)
-		clear(m.processes)
-	}
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
 
 	processes := make([]Process, 0, len(connections))
+	infos := make(map[int32]processInfo)
+
+	// Socket statistics are optional, the sockets are listed without them on failure.
+	stats, _ := readSocketStats()
 
 	for _, conn := range connections {
 		select {
@@ -243,17 +395,17 @@ func (m *ProcessManager) fetchProcesses(ctx context.Context, options ...Option)
 			return ctx.Err()
 
 		default:
-			proc, err := process.NewProcessWithContext(ctx, int32(conn.Pid))
-			if err != nil {
-				// Skip process that we can't get.
-				continue
+			info, ok := infos[conn.Pid]
+			if !ok {
+				info, err = m.processInfo(ctx, conn.Pid)
+				if err != nil {
+					// Skip process that we can't get.
+					continue
+				}
+				infos[conn.Pid] = info
 			}
 
-			name, nameErr := proc.Name()
-			if nameErr != nil {
-				// Skip process if we can't get the name.
-				continue
-			}
+			name := info.name
 
 			var (
 				protocol string
@@ -311,28 +463,135 @@ func (m *ProcessManager) fetchProcesses(ctx context.Context, options ...Option)
 			}
 
 			process := Process{
-				PID:       int(conn.Pid),
-				Name:      name,
-				Port:      int(conn.Laddr.Port),
-				Protocol:  protocol,
-				Status:    status,
-				LocalAddr: fmt.Sprintf("%s:%d", conn.Laddr.IP, conn.Laddr.Port),
+				PID:        int(conn.Pid),
+				Name:       name,
+				Port:       int(conn.Laddr.Port),
+				Protocol:   protocol,
+				Status:     status,
+				LocalAddr:  fmt.Sprintf("%s:%d", conn.Laddr.IP, conn.Laddr.Port),
+				User:       info.user,
+				Cmdline:    info.cmdline,
+				Service:    m.services.Lookup(int(conn.Laddr.Port), protocol),
+				Exposure:   ExposureUnknown,
+				CPUPercent: info.cpuPercent,
+				MemoryRSS:  info.memoryRSS,
+			}
+
+			if addr, err := netip.ParseAddr(conn.Laddr.IP); err == nil {
+				addrPort := netip.AddrPortFrom(addr, uint16(conn.Laddr.Port))
+				process.LocalAddr = addrPort.String()
+				process.Exposure = ClassifyExposure(addr)
+			}
+
+			if conn.Raddr.IP != "" && conn.Raddr.Port != 0 {
+				process.RemoteAddr = fmt.Sprintf("%s:%d", conn.Raddr.IP, conn.Raddr.Port)
+				if addr, err := netip.ParseAddr(conn.Raddr.IP); err == nil {
+					process.RemoteAddr = netip.AddrPortFrom(addr, uint16(conn.Raddr.Port)).String()
+				}
+			}
+
+			if local, err := netip.ParseAddr(conn.Laddr.IP); err == nil {
+				var remote netip.AddrPort
+				if addr, err := netip.ParseAddr(conn.Raddr.IP); err == nil {
+					remote = netip.AddrPortFrom(addr, uint16(conn.Raddr.Port))
+				}
+				key := newSocketKey(protocol, netip.AddrPortFrom(local, uint16(conn.Laddr.Port)), remote)
+				if s, ok := stats[key]; ok {
+					s.apply(&process)
+				}
 			}
 
 			processes = append(processes, process)
 		}
 	}
 
-	m.processes = processes
-	clear(m.pidIndex)
+	// Forget the handles of exited processes.
+	for pid := range m.handles {
+		if _, ok := infos[pid]; !ok {
+			delete(m.handles, pid)
+		}
+	}
 
+	pidIndex := make(map[int]int, len(processes))
 	for i, process := range processes {
-		m.pidIndex[process.PID] = i
+		pidIndex[process.PID] = i
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.bandwidth != nil {
+		m.bandwidth.sample(processes, now)
+	}
+	if m.history != nil {
+		m.history.record(processes, now)
+	}
+
+	m.processes = processes
+	m.pidIndex = pidIndex
+	m.refreshed = now
+
 	return nil
 }
 
+// processHandle is a process handle kept between refreshes. The create time
+// tells a reused PID from the process the handle was made for.
+type processHandle struct {
+	proc       *process.Process
+	createTime int64
+}
+
+// processInfo collects the process details. The process handle is reused between
+// refreshes, so the CPU usage is measured over the refresh interval.
+// Only the name is required, other details are left empty when they can't be read.
+// It must be called with refreshMu held.
+func (m *ProcessManager) processInfo(ctx context.Context, pid int32) (processInfo, error) {
+	fresh, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		delete(m.handles, pid)
+		return processInfo{}, fmt.Errorf("get process %d: %w", pid, err)
+	}
+
+	createTime, err := fresh.CreateTimeWithContext(ctx)
+	if err != nil {
+		delete(m.handles, pid)
+		return processInfo{}, fmt.Errorf("get process %d create time: %w", pid, err)
+	}
+
+	handle, ok := m.handles[pid]
+	if !ok || handle.createTime != createTime {
+		// The PID belongs to another process now, its CPU usage starts over.
+		handle = processHandle{proc: fresh, createTime: createTime}
+		m.handles[pid] = handle
+	}
+
+	proc := handle.proc
+
+	name, err := proc.NameWithContext(ctx)
+	if err != nil {
+		delete(m.handles, pid)
+		return processInfo{}, fmt.Errorf("get process %d name: %w", pid, err)
+	}
+
+	info := processInfo{name: name}
+
+	if user, err := proc.UsernameWithContext(ctx); err == nil {
+		info.user = user
+	}
+	if cmdline, err := proc.CmdlineWithContext(ctx); err == nil {
+		info.cmdline = cmdline
+	}
+	if cpu, err := proc.PercentWithContext(ctx, 0); err == nil {
+		info.cpuPercent = cpu
+	}
+	if memory, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		info.memoryRSS = memory.RSS
+	}
+
+	return info, nil
+}
+
 func (m *ProcessManager) connections(ctx context.Context, options Options) ([]netutil.ConnectionStat, error) {
 	connections, err := netutil.ConnectionsWithContext(ctx, options.FilterProtocol)
 	if err != nil {
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// searchMode selects how the search box input is interpreted.
+type searchMode int
+
+const (
+	// searchModeQuery interprets the input with the query syntax of parseQuery.
+	searchModeQuery searchMode = iota
+	// searchModeRegex interprets the input as a case-insensitive regular expression.
+	searchModeRegex
+	// searchModeFuzzy interprets the input as a fuzzy pattern and ranks results.
+	searchModeFuzzy
+)
+
+// searchQuery is a parsed TUI search query.
+//
+// The query consists of terms separated by whitespace which all have to match.
+// Groups of terms can be combined with "|" or "OR", a process matches the query
+// when it matches any of the groups. A term is either a plain word matched as
+// a substring against every field, or a "field:value" pair, optionally negated
+// with a leading "-":
+//
+//	port:80 port:3000-3999 pid:1234 proc:node state:listen addr:127.0.0.1
+//	proto:tcp service:redis exposure:wildcard -proc:chrome
+type searchQuery struct {
+	mode    searchMode
+	groups  [][]queryTerm
+	regex   *regexp.Regexp
+	pattern string
+}
+
+// searchResult is a process matched by the search query.
+type searchResult struct {
+	process Process
+	// highlights holds byte offsets of the process name characters to highlight.
+	highlights []int
+}
+
+// queryTerm is a single condition of the search query.
+type queryTerm struct {
+	field  string
+	value  string
+	low    int
+	high   int
+	negate bool
+}
+
+// queryFields maps the accepted field names and their aliases to canonical names.
+var queryFields = map[string]string{
+	"port":     "port",
+	"pid":      "pid",
+	"proc":     "proc",
+	"process":  "proc",
+	"name":     "proc",
+	"state":    "state",
+	"status":   "state",
+	"addr":     "addr",
+	"address":  "addr",
+	"proto":    "proto",
+	"protocol": "proto",
+	"service":  "service",
+	"svc":      "service",
+	"exposure": "exposure",
+}
+
+// newSearchQuery parses the input according to the search mode.
+func newSearchQuery(input string, mode searchMode) (searchQuery, error) {
+	switch mode {
+	case searchModeRegex:
+		if strings.TrimSpace(input) == "" {
+			return searchQuery{mode: mode}, nil
+		}
+
+		regex, err := regexp.Compile("(?i)" + input)
+		if err != nil {
+			return searchQuery{}, fmt.Errorf("invalid regular expression: %w", err)
+		}
+
+		return searchQuery{mode: mode, regex: regex}, nil
+
+	case searchModeFuzzy:
+		return searchQuery{mode: mode, pattern: strings.TrimSpace(input)}, nil
+
+	default:
+		return parseQuery(input)
+	}
+}
+
+// parseQuery parses the search query.
+func parseQuery(input string) (searchQuery, error) {
+	var (
+		query searchQuery
+		group []queryTerm
+	)
+
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return query, err
+	}
+
+	for i, token := range tokens {
+		if token == "|" || token == "OR" {
+			if len(group) == 0 || i == len(tokens)-1 {
+				return query, fmt.Errorf("%q must be placed between terms", token)
+			}
+			query.groups = append(query.groups, group)
+			group = nil
+			continue
+		}
+
+		term, err := parseQueryTerm(token)
+		if err != nil {
+			return query, err
+		}
+		group = append(group, term)
+	}
+
+	if len(group) > 0 {
+		query.groups = append(query.groups, group)
+	}
+
+	return query, nil
+}
+
+// tokenizeQuery splits the query by whitespace and "|", keeping double quoted values together.
+func tokenizeQuery(input string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+			current.WriteRune(r)
+		case r == '|':
+			flush()
+			tokens = append(tokens, "|")
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	flush()
+
+	return tokens, nil
+}
+
+func parseQueryTerm(token string) (queryTerm, error) {
+	var term queryTerm
+
+	if strings.HasPrefix(token, "-") {
+		term.negate = true
+		token = token[1:]
+	}
+
+	if token == "" {
+		return term, fmt.Errorf("nothing to negate after \"-\"")
+	}
+
+	name, value, qualified := strings.Cut(token, ":")
+	if !qualified {
+		term.value = strings.ToLower(token)
+		return term, nil
+	}
+
+	field, ok := queryFields[strings.ToLower(name)]
+	if !ok {
+		return term, fmt.Errorf("unknown field %q", name)
+	}
+
+	if value == "" {
+		return term, fmt.Errorf("missing value for %q", name)
+	}
+
+	term.field = field
+	term.value = strings.ToLower(value)
+
+	if field == "port" || field == "pid" {
+		low, high, err := parseNumberRange(value)
+		if err != nil {
+			return term, fmt.Errorf("invalid %s %q", field, value)
+		}
+		term.low, term.high = low, high
+	}
+
+	return term, nil
+}
+
+// parseNumberRange parses a number or an inclusive "low-high" range.
+func parseNumberRange(value string) (int, int, error) {
+	lowValue, highValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		highValue = lowValue
+	}
+
+	low, err := strconv.Atoi(lowValue)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	high, err := strconv.Atoi(highValue)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if low > high {
+		return 0, 0, fmt.Errorf("empty range")
+	}
+
+	return low, high, nil
+}
+
+// empty reports whether the query has no terms and thus matches everything.
+func (q searchQuery) empty() bool {
+	switch q.mode {
+	case searchModeRegex:
+		return q.regex == nil
+	case searchModeFuzzy:
+		return q.pattern == ""
+	default:
+		return len(q.groups) == 0
+	}
+}
+
+// filter returns the processes matching the query.
+// In fuzzy mode results are ordered by rank, otherwise the order is kept.
+func (q searchQuery) filter(processes []Process) []searchResult {
+	results := make([]searchResult, 0, len(processes))
+
+	switch {
+	case q.empty():
+		for _, process := range processes {
+			results = append(results, searchResult{process: process})
+		}
+
+	case q.mode == searchModeFuzzy:
+		matches := fuzzy.FindFrom(q.pattern, fuzzySource(processes))
+
+		for _, match := range matches {
+			process := processes[match.Index]
+			highlights := make([]int, 0, len(match.MatchedIndexes))
+			for _, index := range match.MatchedIndexes {
+				if index < len(process.Name) {
+					highlights = append(highlights, index)
+				}
+			}
+			results = append(results, searchResult{process: process, highlights: highlights})
+		}
+
+	case q.mode == searchModeRegex:
+		for _, process := range processes {
+			if !q.regexMatches(process) {
+				continue
+			}
+
+			var highlights []int
+			for _, loc := range q.regex.FindAllStringIndex(process.Name, -1) {
+				for i := loc[0]; i < loc[1]; i++ {
+					highlights = append(highlights, i)
+				}
+			}
+			results = append(results, searchResult{process: process, highlights: highlights})
+		}
+
+	default:
+		for _, process := range processes {
+			if q.matches(process) {
+				results = append(results, searchResult{process: process})
+			}
+		}
+	}
+
+	return results
+}
+
+// fuzzySource exposes processes to fuzzy matching as "name port service address".
+type fuzzySource []Process
+
+func (s fuzzySource) String(i int) string {
+	p := s[i]
+	return p.Name + " " + strconv.Itoa(p.Port) + " " + p.Service + " " + p.LocalAddr
+}
+
+func (s fuzzySource) Len() int { return len(s) }
+
+func (q searchQuery) regexMatches(process Process) bool {
+	fields := []string{
+		process.Name,
+		strconv.Itoa(process.PID),
+		process.Protocol,
+		strconv.Itoa(process.Port),
+		process.Service,
+		process.Status,
+		process.LocalAddr,
+	}
+
+	for _, field := range fields {
+		if q.regex.MatchString(field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches reports whether the process matches the query groups.
+func (q searchQuery) matches(process Process) bool {
+	if len(q.groups) == 0 {
+		return true
+	}
+
+	for _, group := range q.groups {
+		if groupMatches(process, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func groupMatches(process Process, group []queryTerm) bool {
+	for _, term := range group {
+		if term.matches(process) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the process satisfies the term ignoring negation.
+func (t queryTerm) matches(process Process) bool {
+	switch t.field {
+	case "port":
+		return process.Port >= t.low && process.Port <= t.high
+	case "pid":
+		return process.PID >= t.low && process.PID <= t.high
+	case "proc":
+		return strings.Contains(strings.ToLower(process.Name), t.value)
+	case "state":
+		return strings.HasPrefix(strings.ToLower(process.Status), t.value)
+	case "addr":
+		return strings.Contains(strings.ToLower(process.LocalAddr), t.value)
+	case "proto":
+		return strings.HasPrefix(strings.ToLower(process.Protocol), t.value)
+	case "service":
+		return strings.Contains(strings.ToLower(process.Service), t.value)
+	case "exposure":
+		return strings.HasPrefix(process.Exposure, t.value)
+	default:
+		return matchesTokens(process, []string{t.value})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    [][]queryTerm
+		wantErr bool
+	}{
+		"empty": {
+			input: "  ",
+			want:  nil,
+		},
+		"free text is lowercased": {
+			input: "Node",
+			want:  [][]queryTerm{{{value: "node"}}},
+		},
+		"terms of a group": {
+			input: "proc:node state:LISTEN",
+			want:  [][]queryTerm{{{field: "proc", value: "node"}, {field: "state", value: "listen"}}},
+		},
+		"field aliases": {
+			input: "process:a name:b status:c address:d protocol:e svc:f",
+			want: [][]queryTerm{{
+				{field: "proc", value: "a"},
+				{field: "proc", value: "b"},
+				{field: "state", value: "c"},
+				{field: "addr", value: "d"},
+				{field: "proto", value: "e"},
+				{field: "service", value: "f"},
+			}},
+		},
+		"port": {
+			input: "port:80",
+			want:  [][]queryTerm{{{field: "port", value: "80", low: 80, high: 80}}},
+		},
+		"port range": {
+			input: "port:3000-3999",
+			want:  [][]queryTerm{{{field: "port", value: "3000-3999", low: 3000, high: 3999}}},
+		},
+		"negated": {
+			input: "-proc:chrome",
+			want:  [][]queryTerm{{{field: "proc", value: "chrome", negate: true}}},
+		},
+		"alternatives": {
+			input: "port:80 | pid:1234 OR redis",
+			want: [][]queryTerm{
+				{{field: "port", value: "80", low: 80, high: 80}},
+				{{field: "pid", value: "1234", low: 1234, high: 1234}},
+				{{value: "redis"}},
+			},
+		},
+		"pipe without spaces": {
+			input: "a|b",
+			want:  [][]queryTerm{{{value: "a"}}, {{value: "b"}}},
+		},
+		"quoted value": {
+			input: `proc:"Google Chrome" "a | b"`,
+			want:  [][]queryTerm{{{field: "proc", value: "google chrome"}, {value: "a | b"}}},
+		},
+		"unterminated quote": {
+			input:   `proc:"node`,
+			wantErr: true,
+		},
+		"leading alternative": {
+			input:   "| port:80",
+			wantErr: true,
+		},
+		"trailing alternative": {
+			input:   "port:80 OR",
+			wantErr: true,
+		},
+		"nothing to negate": {
+			input:   "-",
+			wantErr: true,
+		},
+		"unknown field": {
+			input:   "user:root",
+			wantErr: true,
+		},
+		"missing value": {
+			input:   "port:",
+			wantErr: true,
+		},
+		"invalid port": {
+			input:   "port:http",
+			wantErr: true,
+		},
+		"empty range": {
+			input:   "port:90-80",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := parseQuery(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseQuery(%q) error = nil, want an error", tc.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tc.input, err)
+			}
+
+			if !reflect.DeepEqual(query.groups, tc.want) {
+				t.Errorf("parseQuery(%q) = %+v, want %+v", tc.input, query.groups, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchQueryMatches(t *testing.T) {
+	nginx := Process{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:80"}
+	node := Process{PID: 20, Name: "node", Port: 3000, Protocol: ProtocolTCP6, Status: StatusListen, LocalAddr: "[::1]:3000"}
+
+	tests := map[string]struct {
+		input string
+		want  []int
+	}{
+		"everything":       {input: "", want: []int{10, 20}},
+		"port":             {input: "port:80", want: []int{10}},
+		"port range":       {input: "port:1000-4000", want: []int{20}},
+		"all terms":        {input: "proc:node port:80", want: []int{}},
+		"any group":        {input: "proc:node | port:80", want: []int{10, 20}},
+		"negated":          {input: "-proc:nginx", want: []int{20}},
+		"free text":        {input: "ngi", want: []int{10}},
+		"protocol family":  {input: "proto:tcp6", want: []int{20}},
+		"address":          {input: "addr:::1", want: []int{20}},
+		"pid":              {input: "pid:20", want: []int{20}},
+		"state any case":   {input: "state:Listen", want: []int{10, 20}},
+		"negated and term": {input: "state:listen -port:3000", want: []int{10}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := parseQuery(tc.input)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tc.input, err)
+			}
+
+			got := make([]int, 0)
+			for _, result := range query.filter([]Process{nginx, node}) {
+				got = append(got, result.process.PID)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("filter(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	searchStoreFileName = "search.yaml"
+	// maxSearchHistory limits the number of remembered search queries.
+	maxSearchHistory = 100
+)
+
+// searchModeNames maps search modes to the names used in presets.
+var searchModeNames = map[searchMode]string{
+	searchModeQuery: "query",
+	searchModeRegex: "regex",
+	searchModeFuzzy: "fuzzy",
+}
+
+// parseSearchMode returns the search mode by its name, empty name means the query mode.
+func parseSearchMode(name string) (searchMode, error) {
+	if name == "" {
+		return searchModeQuery, nil
+	}
+
+	for mode, modeName := range searchModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+
+	return searchModeQuery, fmt.Errorf("invalid search mode: %s", name)
+}
+
+// SearchPreset is a named combination of a search query and filter toggles.
+type SearchPreset struct {
+	Name    string   `yaml:"name"`
+	Query   string   `yaml:"query,omitempty"`
+	Mode    string   `yaml:"mode,omitempty"`
+	Filters []string `yaml:"filters,omitempty"`
+}
+
+// SearchHistoryEntry is a previous search query with the mode it was made in.
+type SearchHistoryEntry struct {
+	Query string `yaml:"query"`
+	Mode  string `yaml:"mode,omitempty"`
+}
+
+// newSearchHistoryEntry captures the query and the search mode.
+func newSearchHistoryEntry(query string, mode searchMode) SearchHistoryEntry {
+	entry := SearchHistoryEntry{Query: query}
+	if mode != searchModeQuery {
+		entry.Mode = searchModeNames[mode]
+	}
+
+	return entry
+}
+
+// SearchStore persists the search history and presets
+// in the search.yaml file under the XDG config directory.
+type SearchStore struct {
+	// History holds the search queries, the most recent first.
+	History []SearchHistoryEntry `yaml:"history"`
+	Presets []SearchPreset       `yaml:"presets"`
+
+	path string
+}
+
+// LoadSearchStore reads the search store, a missing file results in an empty store.
+func LoadSearchStore() (*SearchStore, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &SearchStore{path: filepath.Join(dir, searchStoreFileName)}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("read search store: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("parse search store %s: %w", store.path, err)
+	}
+
+	for _, entry := range store.History {
+		if _, err := parseSearchMode(entry.Mode); err != nil {
+			return nil, fmt.Errorf("history %q: %w", entry.Query, err)
+		}
+	}
+
+	for _, preset := range store.Presets {
+		if _, err := preset.filterState(); err != nil {
+			return nil, fmt.Errorf("preset %q: %w", preset.Name, err)
+		}
+		if _, err := parseSearchMode(preset.Mode); err != nil {
+			return nil, fmt.Errorf("preset %q: %w", preset.Name, err)
+		}
+	}
+
+	return store, nil
+}
+
+// Save writes the store to disk creating the config directory if needed.
+func (s *SearchStore) Save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal search store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write search store: %w", err)
+	}
+
+	return nil
+}
+
+// AddHistory puts the query made in the mode at the top of the history removing its older occurrence.
+func (s *SearchStore) AddHistory(query string, mode searchMode) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+
+	entry := newSearchHistoryEntry(query, mode)
+
+	s.History = slices.DeleteFunc(s.History, func(e SearchHistoryEntry) bool { return e == entry })
+	s.History = slices.Insert(s.History, 0, entry)
+
+	if len(s.History) > maxSearchHistory {
+		s.History = s.History[:maxSearchHistory]
+	}
+}
+
+// Preset returns the preset by name.
+func (s *SearchStore) Preset(name string) (SearchPreset, bool) {
+	for _, preset := range s.Presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return SearchPreset{}, false
+}
+
+// SetPreset adds the preset or replaces the one with the same name.
+func (s *SearchStore) SetPreset(preset SearchPreset) {
+	for i := range s.Presets {
+		if s.Presets[i].Name == preset.Name {
+			s.Presets[i] = preset
+			return
+		}
+	}
+	s.Presets = append(s.Presets, preset)
+}
+
+// DeletePreset removes the preset by name.
+func (s *SearchStore) DeletePreset(name string) {
+	s.Presets = slices.DeleteFunc(s.Presets, func(p SearchPreset) bool { return p.Name == name })
+}
+
+// filterState converts the preset filter names to the filter toggles.
+func (p SearchPreset) filterState() (filterState, error) {
+	return parseFilterState(p.Filters)
+}
+
+// newSearchPreset captures the query, the search mode and the filter toggles.
+func newSearchPreset(name, query string, mode searchMode, filters filterState) SearchPreset {
+	preset := SearchPreset{Name: name, Query: query}
+
+	if mode != searchModeQuery {
+		preset.Mode = searchModeNames[mode]
+	}
+
+	for _, label := range filters.activeLabels() {
+		preset.Filters = append(preset.Filters, strings.ToLower(label))
+	}
+
+	return preset
+}
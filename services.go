@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// serviceKey identifies a service by port and transport ("tcp", "udp" or "" for both).
+type serviceKey struct {
+	port      int
+	transport string
+}
+
+// wellKnownServices holds IANA registered names and ports commonly used in development.
+var wellKnownServices = map[serviceKey]string{
+	{20, ""}:       "ftp-data",
+	{21, ""}:       "ftp",
+	{22, ""}:       "ssh",
+	{23, ""}:       "telnet",
+	{25, ""}:       "smtp",
+	{53, ""}:       "dns",
+	{67, "udp"}:    "dhcp",
+	{68, "udp"}:    "dhcp-client",
+	{69, "udp"}:    "tftp",
+	{80, ""}:       "http",
+	{110, ""}:      "pop3",
+	{111, ""}:      "rpcbind",
+	{123, "udp"}:   "ntp",
+	{137, "udp"}:   "netbios-ns",
+	{138, "udp"}:   "netbios-dgm",
+	{139, ""}:      "netbios-ssn",
+	{143, ""}:      "imap",
+	{161, "udp"}:   "snmp",
+	{162, "udp"}:   "snmp-trap",
+	{389, ""}:      "ldap",
+	{443, ""}:      "https",
+	{445, ""}:      "smb",
+	{465, ""}:      "smtps",
+	{500, "udp"}:   "isakmp",
+	{514, "udp"}:   "syslog",
+	{515, ""}:      "printer",
+	{546, "udp"}:   "dhcpv6-client",
+	{547, "udp"}:   "dhcpv6-server",
+	{587, ""}:      "submission",
+	{631, ""}:      "ipp",
+	{636, ""}:      "ldaps",
+	{853, ""}:      "dns-over-tls",
+	{873, ""}:      "rsync",
+	{993, ""}:      "imaps",
+	{995, ""}:      "pop3s",
+	{1080, ""}:     "socks",
+	{1194, ""}:     "openvpn",
+	{1433, ""}:     "mssql",
+	{1521, ""}:     "oracle",
+	{1883, ""}:     "mqtt",
+	{1900, "udp"}:  "ssdp",
+	{2049, ""}:     "nfs",
+	{2181, ""}:     "zookeeper",
+	{2375, ""}:     "docker",
+	{2376, ""}:     "docker-tls",
+	{2379, ""}:     "etcd-client",
+	{2380, ""}:     "etcd-peer",
+	{3000, ""}:     "dev-server",
+	{3306, ""}:     "mysql",
+	{3389, ""}:     "rdp",
+	{4222, ""}:     "nats",
+	{4317, ""}:     "otlp-grpc",
+	{4318, ""}:     "otlp-http",
+	{5000, ""}:     "dev-server",
+	{5037, ""}:     "adb",
+	{5173, ""}:     "vite",
+	{5222, ""}:     "xmpp",
+	{5353, "udp"}:  "mdns",
+	{5432, ""}:     "postgresql",
+	{5601, ""}:     "kibana",
+	{5672, ""}:     "amqp",
+	{5900, ""}:     "vnc",
+	{6379, ""}:     "redis",
+	{6443, ""}:     "kubernetes-api",
+	{7001, ""}:     "cassandra",
+	{8000, ""}:     "http-alt",
+	{8008, ""}:     "http-alt",
+	{8080, ""}:     "http-proxy",
+	{8081, ""}:     "http-alt",
+	{8086, ""}:     "influxdb",
+	{8443, ""}:     "https-alt",
+	{8500, ""}:     "consul",
+	{8888, ""}:     "jupyter",
+	{9000, ""}:     "php-fpm",
+	{9042, ""}:     "cassandra-cql",
+	{9090, ""}:     "prometheus",
+	{9092, ""}:     "kafka",
+	{9093, ""}:     "alertmanager",
+	{9100, ""}:     "node-exporter",
+	{9200, ""}:     "elasticsearch",
+	{9300, ""}:     "elasticsearch-transport",
+	{9418, ""}:     "git",
+	{10250, ""}:    "kubelet",
+	{11211, ""}:    "memcached",
+	{15672, ""}:    "rabbitmq-management",
+	{27017, ""}:    "mongodb",
+	{51820, "udp"}: "wireguard",
+}
+
+// ServiceRegistry resolves port numbers to service names.
+// User overrides take precedence over the built-in table.
+type ServiceRegistry struct {
+	overrides map[serviceKey]string
+}
+
+// NewServiceRegistry creates a new ServiceRegistry with the given overrides.
+// Override keys are ports optionally followed by a transport, e.g. "8080" or "5353/udp".
+func NewServiceRegistry(overrides map[string]string) (*ServiceRegistry, error) {
+	registry := &ServiceRegistry{overrides: make(map[serviceKey]string, len(overrides))}
+
+	for spec, name := range overrides {
+		key, err := parseServiceKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		registry.overrides[key] = name
+	}
+
+	return registry, nil
+}
+
+// Lookup returns the service name for the port and protocol or an empty string.
+func (r *ServiceRegistry) Lookup(port int, protocol string) string {
+	transport := protocolTransport(protocol)
+
+	if r != nil {
+		if name, ok := r.overrides[serviceKey{port, transport}]; ok {
+			return name
+		}
+		if name, ok := r.overrides[serviceKey{port, ""}]; ok {
+			return name
+		}
+	}
+
+	if name, ok := wellKnownServices[serviceKey{port, transport}]; ok {
+		return name
+	}
+
+	return wellKnownServices[serviceKey{port, ""}]
+}
+
+// protocolTransport maps protocol names like "TCP6" to "tcp" or "udp".
+func protocolTransport(protocol string) string {
+	protocol = strings.ToLower(protocol)
+
+	switch {
+	case strings.HasPrefix(protocol, "tcp"):
+		return "tcp"
+	case strings.HasPrefix(protocol, "udp"):
+		return "udp"
+	default:
+		return ""
+	}
+}
+
+func parseServiceKey(spec string) (serviceKey, error) {
+	portPart, transport, _ := strings.Cut(strings.TrimSpace(spec), "/")
+
+	port, err := strconv.Atoi(portPart)
+	if err != nil || port < 1 || port > 65535 {
+		return serviceKey{}, fmt.Errorf("invalid service port: %q", spec)
+	}
+
+	transport = strings.ToLower(transport)
+	switch transport {
+	case "", "tcp", "udp":
+	default:
+		return serviceKey{}, fmt.Errorf("invalid service protocol: %q", spec)
+	}
+
+	return serviceKey{port: port, transport: transport}, nil
+}
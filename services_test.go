@@ -0,0 +1,136 @@
+package main
+
+import "testing"
+
+func TestParseServiceKey(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    serviceKey
+		wantErr bool
+	}{
+		"port": {
+			input: "8080",
+			want:  serviceKey{port: 8080},
+		},
+		"port and transport": {
+			input: "5353/udp",
+			want:  serviceKey{port: 5353, transport: "udp"},
+		},
+		"transport any case": {
+			input: " 22/TCP ",
+			want:  serviceKey{port: 22, transport: "tcp"},
+		},
+		"not a port": {
+			input:   "http",
+			wantErr: true,
+		},
+		"port zero": {
+			input:   "0",
+			wantErr: true,
+		},
+		"above 65535": {
+			input:   "65536/tcp",
+			wantErr: true,
+		},
+		"unknown transport": {
+			input:   "80/sctp",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseServiceKey(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseServiceKey(%q) = %v, want an error", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseServiceKey(%q) error = %v", tc.input, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("parseServiceKey(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestServiceRegistryLookup(t *testing.T) {
+	registry, err := NewServiceRegistry(map[string]string{
+		"8080":     "api",
+		"5353/udp": "discovery",
+		"9999/tcp": "admin",
+	})
+	if err != nil {
+		t.Fatalf("NewServiceRegistry() error = %v", err)
+	}
+
+	tests := map[string]struct {
+		registry *ServiceRegistry
+		port     int
+		protocol string
+		want     string
+	}{
+		"well-known": {
+			registry: registry,
+			port:     22,
+			protocol: ProtocolTCP,
+			want:     "ssh",
+		},
+		"well-known of the transport": {
+			registry: registry,
+			port:     123,
+			protocol: ProtocolUDP,
+			want:     "ntp",
+		},
+		"well-known of another transport": {
+			registry: registry,
+			port:     123,
+			protocol: ProtocolTCP,
+		},
+		"override of any transport": {
+			registry: registry,
+			port:     8080,
+			protocol: ProtocolTCP6,
+			want:     "api",
+		},
+		"override of the transport": {
+			registry: registry,
+			port:     5353,
+			protocol: "udp6",
+			want:     "discovery",
+		},
+		"override of another transport": {
+			registry: registry,
+			port:     9999,
+			protocol: ProtocolUDP,
+		},
+		"unknown port": {
+			registry: registry,
+			port:     12345,
+			protocol: ProtocolTCP,
+		},
+		"nil registry": {
+			port:     443,
+			protocol: ProtocolTCP,
+			want:     "https",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.registry.Lookup(tc.port, tc.protocol); got != tc.want {
+				t.Errorf("Lookup(%d, %q) = %q, want %q", tc.port, tc.protocol, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewServiceRegistryInvalid(t *testing.T) {
+	if _, err := NewServiceRegistry(map[string]string{"80/sctp": "web"}); err == nil {
+		t.Error("NewServiceRegistry() error = nil, want an error")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// ErrReadOnly indicates that the action is not available for a saved snapshot.
+	ErrReadOnly = Error("not available in read-only snapshot mode")
+)
+
+// Snapshot represents the state of ProcessManager at a point in time.
+type Snapshot struct {
+	Host      string    `json:"host"`
+	CreatedAt time.Time `json:"created_at"`
+	Version   string    `json:"version"`
+	Processes []Process `json:"processes"`
+}
+
+// Snapshot returns the current state of the manager with the sockets matching the options.
+func (m *ProcessManager) Snapshot(ctx context.Context, options ...Option) (Snapshot, error) {
+	processes, err := m.Processes(ctx, options...)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("list processes: %w", err)
+	}
+
+	if m.origin != nil {
+		snapshot := *m.origin
+		snapshot.Processes = processes
+		return snapshot, nil
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get hostname: %w", err)
+	}
+
+	return Snapshot{
+		Host:      host,
+		CreatedAt: time.Now(),
+		Version:   Version,
+		Processes: processes,
+	}, nil
+}
+
+// Origin returns the snapshot the manager was loaded from, or nil for live data.
+func (m *ProcessManager) Origin() *Snapshot { return m.origin }
+
+// defaultSnapshotPath returns a file name for a snapshot based on its host and time.
+func defaultSnapshotPath(snapshot Snapshot) string {
+	return fmt.Sprintf("%s-%s-%s.json", cmdName, snapshot.Host, snapshot.CreatedAt.Format("20060102T150405"))
+}
+
+// WriteSnapshot writes the snapshot to w as indented JSON.
+func WriteSnapshot(w io.Writer, snapshot Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(snapshot); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSnapshot writes the snapshot to the file.
+func SaveSnapshot(path string, snapshot Snapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create snapshot file: %w", err)
+	}
+
+	if err := WriteSnapshot(file, snapshot); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot reads a snapshot previously written by SaveSnapshot.
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := Snapshot{
+		Host:      "devbox",
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC),
+		Version:   "1.2.3",
+		Processes: []Process{
+			{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen, LocalAddr: "0.0.0.0:80", Cmdline: "nginx -g daemon off;", Service: "http", Exposure: ExposureWildcard},
+			{PID: 20, Name: "dnsmasq", Port: 53, Protocol: ProtocolUDP, LocalAddr: "127.0.0.1:53", Exposure: ExposureLoopback},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), defaultSnapshotPath(snapshot))
+	if err := SaveSnapshot(path, snapshot); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("LoadSnapshot() = %+v, want %+v", loaded, snapshot)
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"missing file": filepath.Join(dir, "missing.json"),
+		"invalid json": invalid,
+	}
+
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadSnapshot(path); err == nil {
+				t.Errorf("LoadSnapshot(%s) error = nil, want an error", path)
+			}
+		})
+	}
+}
+
+func TestProcessManagerFromSnapshot(t *testing.T) {
+	snapshot := Snapshot{
+		Host:      "devbox",
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC),
+		Processes: []Process{
+			{PID: 10, Name: "nginx", Port: 80, Protocol: ProtocolTCP, Status: StatusListen},
+			{PID: 20, Name: "node", Port: 3000, Protocol: ProtocolTCP, Status: "ESTABLISHED"},
+		},
+	}
+
+	pm := NewProcessManagerFromSnapshot(snapshot)
+	defer pm.Stop()
+
+	if pm.Origin() == nil {
+		t.Fatal("Origin() = nil, want the snapshot")
+	}
+
+	got, err := pm.Snapshot(context.Background(), WithShowListenOnly(true))
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if got.Host != snapshot.Host || !got.CreatedAt.Equal(snapshot.CreatedAt) {
+		t.Errorf("Snapshot() = %s at %v, want %s at %v", got.Host, got.CreatedAt, snapshot.Host, snapshot.CreatedAt)
+	}
+	if len(got.Processes) != 1 || got.Processes[0].PID != 10 {
+		t.Errorf("Snapshot() processes = %+v, want the nginx listener", got.Processes)
+	}
+
+	if _, err := pm.FreePorts(context.Background(), FreePortQuery{Near: 8080}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("FreePorts() error = %v, want %v", err, ErrReadOnly)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// socketKey identifies a socket by its transport and addresses.
+// IPv4-mapped IPv6 addresses are unmapped and the remote address of
+// unconnected sockets is left zero, so keys from different sources match.
+type socketKey struct {
+	transport string
+	local     netip.AddrPort
+	remote    netip.AddrPort
+}
+
+// newSocketKey creates the key of a socket of the protocol ("tcp", "tcp6", "udp", ...).
+func newSocketKey(protocol string, local, remote netip.AddrPort) socketKey {
+	key := socketKey{
+		transport: "udp",
+		local:     netip.AddrPortFrom(local.Addr().Unmap(), local.Port()),
+	}
+	if strings.HasPrefix(strings.ToLower(protocol), "tcp") {
+		key.transport = "tcp"
+	}
+	if remote.Port() != 0 {
+		key.remote = netip.AddrPortFrom(remote.Addr().Unmap(), remote.Port())
+	}
+
+	return key
+}
+
+// socketStats holds the queue and traffic counters of a socket.
+// For listening TCP sockets recvQ is the accept queue length and sendQ its limit.
+type socketStats struct {
+	recvQ         int
+	sendQ         int
+	retransmits   int
+	rtt           float64
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+// apply copies the counters to the process.
+func (s socketStats) apply(p *Process) {
+	p.RecvQ = s.recvQ
+	p.SendQ = s.sendQ
+	p.Retransmits = s.retransmits
+	p.RTT = s.rtt
+	p.BytesSent = s.bytesSent
+	p.BytesReceived = s.bytesReceived
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// procNetFiles maps the /proc/net socket tables to their protocols.
+var procNetFiles = map[string]string{
+	"/proc/net/tcp":  "tcp",
+	"/proc/net/tcp6": "tcp6",
+	"/proc/net/udp":  "udp",
+	"/proc/net/udp6": "udp6",
+}
+
+// readSocketStats reads the queue sizes and retransmits of all sockets from /proc/net.
+// The TCP sockets are then completed with the accept queue limit of listeners, RTT
+// and byte counters from sock_diag, which older kernels may not provide in full.
+func readSocketStats() (map[socketKey]socketStats, error) {
+	stats := make(map[socketKey]socketStats)
+
+	for path, protocol := range procNetFiles {
+		if err := readProcNet(path, protocol, stats); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		if err := readTCPDiag(family, stats); err != nil {
+			// The /proc/net counters are still valid without sock_diag,
+			// and the other family may still be diagnosed.
+			continue
+		}
+	}
+
+	return stats, nil
+}
+
+// readProcNet parses a /proc/net/{tcp,udp}[6] table into the stats.
+func readProcNet(path, protocol string, stats map[socketKey]socketStats) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header.
+
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+
+		local, err := parseProcNetAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := parseProcNetAddr(fields[2])
+		if err != nil {
+			continue
+		}
+
+		tx, rx, ok := strings.Cut(fields[4], ":")
+		if !ok {
+			continue
+		}
+		sendQ, _ := strconv.ParseUint(tx, 16, 32)
+		recvQ, _ := strconv.ParseUint(rx, 16, 32)
+		retransmits, _ := strconv.ParseUint(fields[6], 16, 32)
+
+		stats[newSocketKey(protocol, local, remote)] = socketStats{
+			recvQ:       int(recvQ),
+			sendQ:       int(sendQ),
+			retransmits: int(retransmits),
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// parseProcNetAddr parses an address such as "0100007F:1F90". The IP is printed
+// as 32-bit words in host byte order and the port in hex.
+func parseProcNetAddr(s string) (netip.AddrPort, error) {
+	ipHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return netip.AddrPort{}, fmt.Errorf("invalid address: %s", s)
+	}
+
+	raw, err := hex.DecodeString(ipHex)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.AddrPort{}, fmt.Errorf("invalid address: %s", s)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(raw[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid port: %s", s)
+	}
+
+	addr, _ := netip.AddrFromSlice(raw)
+
+	return netip.AddrPortFrom(addr, uint16(port)), nil
+}
+
+// sock_diag constants from linux/sock_diag.h and linux/inet_diag.h.
+const (
+	sockDiagByFamily = 20
+	inetDiagInfo     = 2
+	tcpListenState   = 10
+)
+
+// inetDiagSockID is struct inet_diag_sockid, ports and addresses are in network byte order.
+type inetDiagSockID struct {
+	SPort  [2]byte
+	DPort  [2]byte
+	Src    [16]byte
+	Dst    [16]byte
+	If     uint32
+	Cookie [2]uint32
+}
+
+// inetDiagReqV2 is struct inet_diag_req_v2.
+type inetDiagReqV2 struct {
+	Family   uint8
+	Protocol uint8
+	Ext      uint8
+	Pad      uint8
+	States   uint32
+	ID       inetDiagSockID
+}
+
+// inetDiagMsg is struct inet_diag_msg.
+type inetDiagMsg struct {
+	Family  uint8
+	State   uint8
+	Timer   uint8
+	Retrans uint8
+	ID      inetDiagSockID
+	Expires uint32
+	RQueue  uint32
+	WQueue  uint32
+	UID     uint32
+	Inode   uint32
+}
+
+// Offsets of the struct tcp_info fields, the struct grew over kernel versions.
+const (
+	tcpInfoRTT           = 68
+	tcpInfoTotalRetrans  = 100
+	tcpInfoBytesAcked    = 120
+	tcpInfoBytesReceived = 128
+	tcpInfoBytesSent     = 200
+)
+
+// readTCPDiag dumps the TCP sockets of the address family with sock_diag
+// and updates their stats with the queues and the TCP_INFO counters.
+func readTCPDiag(family uint8, stats map[socketKey]socketStats) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG)
+	if err != nil {
+		return fmt.Errorf("open sock_diag socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	request := struct {
+		header syscall.NlMsghdr
+		body   inetDiagReqV2
+	}{
+		header: syscall.NlMsghdr{
+			Type:  sockDiagByFamily,
+			Flags: syscall.NLM_F_REQUEST | syscall.NLM_F_DUMP,
+		},
+		body: inetDiagReqV2{
+			Family:   family,
+			Protocol: syscall.IPPROTO_TCP,
+			Ext:      1 << (inetDiagInfo - 1),
+			States:   ^uint32(0),
+		},
+	}
+	request.header.Len = uint32(unsafe.Sizeof(request))
+
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&request)), unsafe.Sizeof(request))
+	if err := syscall.Sendto(fd, data, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return fmt.Errorf("send sock_diag request: %w", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return fmt.Errorf("receive sock_diag response: %w", err)
+		}
+
+		messages, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return fmt.Errorf("parse sock_diag response: %w", err)
+		}
+
+		for _, message := range messages {
+			switch message.Header.Type {
+			case syscall.NLMSG_DONE:
+				return nil
+			case syscall.NLMSG_ERROR:
+				return fmt.Errorf("sock_diag request failed")
+			}
+			applyTCPDiag(message.Data, stats)
+		}
+	}
+}
+
+// applyTCPDiag updates the stats of the socket described by the inet_diag_msg.
+func applyTCPDiag(data []byte, stats map[socketKey]socketStats) {
+	var msg inetDiagMsg
+	size := int(unsafe.Sizeof(msg))
+	if len(data) < size {
+		return
+	}
+	msg = *(*inetDiagMsg)(unsafe.Pointer(&data[0]))
+
+	local := diagAddrPort(msg.Family, msg.ID.Src, msg.ID.SPort)
+	remote := diagAddrPort(msg.Family, msg.ID.Dst, msg.ID.DPort)
+	key := newSocketKey("tcp", local, remote)
+
+	s := stats[key]
+	s.recvQ, s.sendQ = int(msg.RQueue), int(msg.WQueue)
+
+	// The route attributes follow the message aligned to 4 bytes.
+	for attrs := data[(size+3)&^3:]; len(attrs) >= 4; {
+		length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		kind := binary.NativeEndian.Uint16(attrs[2:4])
+		if length < 4 || length > len(attrs) {
+			break
+		}
+
+		if kind == inetDiagInfo && msg.State != tcpListenState {
+			info := attrs[4:length]
+			if len(info) >= tcpInfoTotalRetrans+4 {
+				s.rtt = float64(binary.NativeEndian.Uint32(info[tcpInfoRTT:])) / 1000
+				s.retransmits = int(binary.NativeEndian.Uint32(info[tcpInfoTotalRetrans:]))
+			}
+			if len(info) >= tcpInfoBytesReceived+8 {
+				s.bytesSent = binary.NativeEndian.Uint64(info[tcpInfoBytesAcked:])
+				s.bytesReceived = binary.NativeEndian.Uint64(info[tcpInfoBytesReceived:])
+			}
+			if len(info) >= tcpInfoBytesSent+8 {
+				s.bytesSent = binary.NativeEndian.Uint64(info[tcpInfoBytesSent:])
+			}
+		}
+
+		attrs = attrs[min((length+3)&^3, len(attrs)):]
+	}
+
+	stats[key] = s
+}
+
+// diagAddrPort converts the sock_diag address and port of the family.
+func diagAddrPort(family uint8, addr [16]byte, port [2]byte) netip.AddrPort {
+	ip := netip.AddrFrom16(addr)
+	if family == syscall.AF_INET {
+		ip = netip.AddrFrom4([4]byte(addr[:4]))
+	}
+
+	return netip.AddrPortFrom(ip, binary.BigEndian.Uint16(port[:]))
+}
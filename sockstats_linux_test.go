@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+)
+
+func TestParseProcNetAddr(t *testing.T) {
+	// The kernel prints the address words in host byte order, the samples are from a little-endian host.
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("the samples need a little-endian host")
+	}
+
+	tests := map[string]struct {
+		input   string
+		want    netip.AddrPort
+		wantErr bool
+	}{
+		"ipv4 loopback": {
+			input: "0100007F:1F90",
+			want:  netip.MustParseAddrPort("127.0.0.1:8080"),
+		},
+		"ipv4 wildcard": {
+			input: "00000000:0016",
+			want:  netip.MustParseAddrPort("0.0.0.0:22"),
+		},
+		"ipv4 private": {
+			input: "0200A8C0:01BB",
+			want:  netip.MustParseAddrPort("192.168.0.2:443"),
+		},
+		"ipv6 loopback": {
+			input: "00000000000000000000000001000000:0050",
+			want:  netip.MustParseAddrPort("[::1]:80"),
+		},
+		"ipv6 wildcard": {
+			input: "00000000000000000000000000000000:0035",
+			want:  netip.MustParseAddrPort("[::]:53"),
+		},
+		"ipv4-mapped ipv6": {
+			input: "0000000000000000FFFF00000100007F:1F90",
+			want:  netip.MustParseAddrPort("[::ffff:127.0.0.1]:8080"),
+		},
+		"ipv6 global": {
+			input: "B80D0120000000000000000001000000:0050",
+			want:  netip.MustParseAddrPort("[2001:db8::1]:80"),
+		},
+		"missing port": {
+			input:   "0100007F",
+			wantErr: true,
+		},
+		"invalid hex": {
+			input:   "0100007G:1F90",
+			wantErr: true,
+		},
+		"invalid length": {
+			input:   "00007F:1F90",
+			wantErr: true,
+		},
+		"invalid port": {
+			input:   "0100007F:1FFFF",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseProcNetAddr(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseProcNetAddr(%q) = %v, want an error", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseProcNetAddr(%q) error = %v", tc.input, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("parseProcNetAddr(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// readSocketStats is only implemented on Linux, elsewhere no socket statistics are reported.
+func readSocketStats() (map[socketKey]socketStats, error) {
+	return nil, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import "strings"
+
+// sparkLevels are the bar characters of a sparkline from the lowest to the highest value.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the last width values as bars scaled to the largest of them,
+// right-aligned so the newest value is at the end.
+func sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+
+	values = values[max(len(values)-width, 0):]
+
+	highest := 0.0
+	for _, value := range values {
+		highest = max(highest, value)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(values)))
+
+	for _, value := range values {
+		level := 0
+		if highest > 0 {
+			level = int(value / highest * float64(len(sparkLevels)-1))
+		}
+		b.WriteRune(sparkLevels[clamp(level, 0, len(sparkLevels)-1)])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// summaryTopProcesses is the number of processes listed in the summary by socket count.
+const summaryTopProcesses = 5
+
+var summaryPanelStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("240")).
+	Padding(0, 2)
+
+var summaryLabelStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240")).
+	Width(15)
+
+// Summary holds the socket counts of a set of processes.
+type Summary struct {
+	Sockets           int              `json:"sockets"`
+	Processes         int              `json:"processes"`
+	Protocols         map[string]int   `json:"protocols"`
+	TCPStates         map[string]int   `json:"tcp_states"`
+	WildcardListeners int              `json:"wildcard_listeners"`
+	TopProcesses      []ProcessSockets `json:"top_processes"`
+}
+
+// ProcessSockets is the number of sockets of a process.
+type ProcessSockets struct {
+	ProcessRef
+	Sockets int `json:"sockets"`
+}
+
+// Summarize counts the sockets by protocol and TCP state, the distinct processes
+// and the listeners bound to all interfaces, and lists the top processes by socket count.
+func Summarize(processes []Process, top int) Summary {
+	summary := Summary{
+		Sockets:   len(processes),
+		Protocols: make(map[string]int),
+		TCPStates: make(map[string]int),
+	}
+
+	sockets := make(map[ProcessRef]int)
+	for _, process := range processes {
+		protocol := strings.ToUpper(process.Protocol)
+		summary.Protocols[protocol]++
+
+		if strings.HasPrefix(protocol, ProtocolTCP) {
+			summary.TCPStates[process.Status]++
+		}
+		if isListener(process) && process.Exposure == ExposureWildcard {
+			summary.WildcardListeners++
+		}
+
+		sockets[ProcessRef{PID: process.PID, Name: process.Name}]++
+	}
+
+	summary.Processes = len(sockets)
+
+	summary.TopProcesses = make([]ProcessSockets, 0, len(sockets))
+	for ref, count := range sockets {
+		summary.TopProcesses = append(summary.TopProcesses, ProcessSockets{ProcessRef: ref, Sockets: count})
+	}
+	slices.SortFunc(summary.TopProcesses, func(a, b ProcessSockets) int {
+		if c := cmp.Compare(b.Sockets, a.Sockets); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PID, b.PID)
+	})
+	summary.TopProcesses = summary.TopProcesses[:min(top, len(summary.TopProcesses))]
+
+	return summary
+}
+
+// renderSummary renders the summary panel as wide as the table.
+func renderSummary(summary Summary, width int) string {
+	field := func(label string, values ...string) string {
+		return summaryLabelStyle.Render(label) + strings.Join(values, "  ")
+	}
+
+	top := make([]string, len(summary.TopProcesses))
+	for i, process := range summary.TopProcesses {
+		top[i] = fmt.Sprintf("%s (%d) %d", displayName(process.Name), process.PID, process.Sockets)
+	}
+
+	inline := summaryLabelStyle.UnsetWidth()
+
+	lines := []string{
+		field("Sockets", strconv.Itoa(summary.Sockets),
+			inline.Render("Processes")+" "+strconv.Itoa(summary.Processes),
+			inline.Render("Wildcard listeners")+" "+strconv.Itoa(summary.WildcardListeners),
+		),
+		field("Protocols", formatCounts(summary.Protocols)...),
+		field("TCP states", formatCounts(summary.TCPStates)...),
+		field("Top processes", top...),
+	}
+
+	inner := max(width-summaryPanelStyle.GetHorizontalPadding(), 0)
+	for i, line := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(inner).Render(line)
+	}
+
+	return summaryPanelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// formatCounts formats the counts as "NAME count", the largest first.
+func formatCounts(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	formatted := make([]string, len(names))
+	for i, name := range names {
+		formatted[i] = name + " " + strconv.Itoa(counts[name])
+	}
+
+	return formatted
+}
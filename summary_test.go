@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	processes := []Process{
+		{PID: 10, Name: "nginx", Protocol: ProtocolTCP, Status: StatusListen, Exposure: ExposureWildcard},
+		{PID: 10, Name: "nginx", Protocol: ProtocolTCP, Status: "ESTABLISHED", RemoteAddr: "10.0.0.2:51000", Exposure: ExposureWildcard},
+		{PID: 10, Name: "nginx", Protocol: "tcp6", Status: StatusListen, Exposure: ExposureLoopback},
+		{PID: 20, Name: "dnsmasq", Protocol: ProtocolUDP, Exposure: ExposureWildcard},
+		{PID: 20, Name: "dnsmasq", Protocol: ProtocolUDP, RemoteAddr: "1.1.1.1:53", Exposure: ExposureWildcard},
+		{PID: 30, Name: "sshd", Protocol: ProtocolTCP, Status: StatusListen, Exposure: ExposurePrivate},
+	}
+
+	tests := map[string]struct {
+		processes []Process
+		top       int
+		want      Summary
+	}{
+		"empty": {
+			top: 3,
+			want: Summary{
+				Protocols:    map[string]int{},
+				TCPStates:    map[string]int{},
+				TopProcesses: []ProcessSockets{},
+			},
+		},
+		"counts": {
+			processes: processes,
+			top:       5,
+			want: Summary{
+				Sockets:           6,
+				Processes:         3,
+				Protocols:         map[string]int{"TCP": 3, "TCP6": 1, "UDP": 2},
+				TCPStates:         map[string]int{StatusListen: 3, "ESTABLISHED": 1},
+				WildcardListeners: 2,
+				TopProcesses: []ProcessSockets{
+					{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, Sockets: 3},
+					{ProcessRef: ProcessRef{PID: 20, Name: "dnsmasq"}, Sockets: 2},
+					{ProcessRef: ProcessRef{PID: 30, Name: "sshd"}, Sockets: 1},
+				},
+			},
+		},
+		"top limited": {
+			processes: processes,
+			top:       1,
+			want: Summary{
+				Sockets:           6,
+				Processes:         3,
+				Protocols:         map[string]int{"TCP": 3, "TCP6": 1, "UDP": 2},
+				TCPStates:         map[string]int{StatusListen: 3, "ESTABLISHED": 1},
+				WildcardListeners: 2,
+				TopProcesses: []ProcessSockets{
+					{ProcessRef: ProcessRef{PID: 10, Name: "nginx"}, Sockets: 3},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Summarize(tc.processes, tc.top); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Summarize() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// hexColorPattern matches "#RGB" and "#RRGGBB" colors.
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Theme holds the colors as ANSI 256 color numbers or "#RRGGBB" hex values.
+// An empty color means no color.
+type Theme struct {
+	// Base is the name of the theme the colors are applied on top of,
+	// either a built-in theme or one from the config themes.
+	Base string `yaml:"base"`
+	// Accent colors the title, prompts and info messages.
+	Accent string `yaml:"accent"`
+	// Muted colors borders, hints and the dimmed background.
+	Muted string `yaml:"muted"`
+	// Error colors error messages and failures.
+	Error string `yaml:"error"`
+	// Highlight colors the search matches.
+	Highlight string `yaml:"highlight"`
+	// Success colors passed checks and appeared listeners.
+	Success string `yaml:"success"`
+	// Warning colors changed listeners.
+	Warning string `yaml:"warning"`
+	// Selected colors the selected row, the row is reversed when empty.
+	Selected string `yaml:"selected"`
+	// Loopback, LinkLocal, Private, Wildcard and Public color local addresses by exposure.
+	Loopback  string `yaml:"loopback"`
+	LinkLocal string `yaml:"link_local"`
+	Private   string `yaml:"private"`
+	Wildcard  string `yaml:"wildcard"`
+	Public    string `yaml:"public"`
+}
+
+// themeColor is a named color field of a theme.
+type themeColor struct {
+	name  string
+	value *string
+}
+
+func (t *Theme) colors() []themeColor {
+	return []themeColor{
+		{"accent", &t.Accent},
+		{"muted", &t.Muted},
+		{"error", &t.Error},
+		{"highlight", &t.Highlight},
+		{"success", &t.Success},
+		{"warning", &t.Warning},
+		{"selected", &t.Selected},
+		{"loopback", &t.Loopback},
+		{"link_local", &t.LinkLocal},
+		{"private", &t.Private},
+		{"wildcard", &t.Wildcard},
+		{"public", &t.Public},
+	}
+}
+
+// builtinThemes holds the bundled palettes by name.
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Accent: "205", Muted: "240", Error: "203", Highlight: "214",
+		Success: "78", Warning: "221", Selected: "212",
+		Loopback: "78", LinkLocal: "75", Private: "221", Wildcard: "208", Public: "203",
+	},
+	"light": {
+		Accent: "162", Muted: "244", Error: "160", Highlight: "166",
+		Success: "28", Warning: "136", Selected: "127",
+		Loopback: "28", LinkLocal: "25", Private: "136", Wildcard: "166", Public: "160",
+	},
+	"high-contrast": {
+		Accent: "201", Muted: "250", Error: "196", Highlight: "226",
+		Success: "46", Warning: "226", Selected: "51",
+		Loopback: "46", LinkLocal: "51", Private: "226", Wildcard: "208", Public: "196",
+	},
+	"monochrome": {},
+}
+
+// defaultTheme holds the colors used unless the config selects another theme.
+var defaultTheme = builtinThemes["dark"]
+
+// activeTheme holds the colors in use, set by applyTheme.
+var activeTheme = defaultTheme
+
+// Styles of the command line reports.
+var (
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Success))
+	failureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Error))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Warning))
+)
+
+// merge returns the theme with the colors set in the override replaced.
+func (t Theme) merge(override Theme) Theme {
+	overrides := override.colors()
+	for i, color := range t.colors() {
+		if value := *overrides[i].value; value != "" {
+			*color.value = value
+		}
+	}
+
+	return t
+}
+
+// validate reports colors which are neither ANSI 256 color numbers nor hex values.
+func (t Theme) validate() error {
+	for _, color := range t.colors() {
+		value := *color.value
+		if value == "" || hexColorPattern.MatchString(value) {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		return fmt.Errorf("%s: invalid color %q, use 0-255 or #RRGGBB", color.name, value)
+	}
+
+	return nil
+}
+
+// resolveTheme applies the theme colors on top of its base theme, following the chain
+// of custom themes. Without a base the dark theme is used, or the monochrome one
+// when colors are disabled.
+func resolveTheme(theme Theme, custom map[string]Theme) (Theme, error) {
+	for name := range custom {
+		if _, ok := builtinThemes[name]; ok {
+			return theme, fmt.Errorf("custom theme %q shadows a built-in theme", name)
+		}
+	}
+
+	var (
+		layers = []Theme{theme}
+		seen   []string
+	)
+
+	for name := theme.Base; ; name = layers[len(layers)-1].Base {
+		if name == "" {
+			name = "dark"
+			if lipgloss.ColorProfile() == termenv.Ascii {
+				name = "monochrome"
+			}
+		}
+
+		if builtin, ok := builtinThemes[name]; ok {
+			layers = append(layers, builtin)
+			break
+		}
+
+		if slices.Contains(seen, name) {
+			return theme, fmt.Errorf("theme %q is based on itself", name)
+		}
+		seen = append(seen, name)
+
+		next, ok := custom[name]
+		if !ok {
+			return theme, fmt.Errorf("unknown theme %q, available: %s", name, strings.Join(themeNames(custom), ", "))
+		}
+		layers = append(layers, next)
+	}
+
+	var resolved Theme
+	for i := len(layers) - 1; i >= 0; i-- {
+		if err := layers[i].validate(); err != nil {
+			return theme, err
+		}
+		resolved = resolved.merge(layers[i])
+	}
+	resolved.Base = theme.Base
+
+	return resolved, nil
+}
+
+// themeNames returns the built-in and custom theme names sorted.
+func themeNames(custom map[string]Theme) []string {
+	names := make([]string, 0, len(builtinThemes)+len(custom))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range custom {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// applyTheme sets the colors of the TUI and report styles.
+// It has to be called before the TUI models are created.
+func applyTheme(t Theme) {
+	activeTheme = t
+
+	baseStyle = baseStyle.BorderForeground(lipgloss.Color(t.Muted))
+	headerLeftStyle = headerLeftStyle.Foreground(lipgloss.Color(t.Accent))
+	headerRightStyle = headerRightStyle.Foreground(lipgloss.Color(t.Muted))
+	confirmBoxStyle = confirmBoxStyle.BorderForeground(lipgloss.Color(t.Accent))
+	confirmKillButtonStyle = confirmKillButtonStyle.Foreground(lipgloss.Color(t.Error))
+	confirmCancelButtonStyle = confirmCancelButtonStyle.Foreground(lipgloss.Color(t.Muted))
+	searchInputStyle = searchInputStyle.BorderForeground(lipgloss.Color(t.Muted))
+	searchErrorStyle = searchErrorStyle.Foreground(lipgloss.Color(t.Error))
+	searchHighlightStyle = searchHighlightStyle.Foreground(lipgloss.Color(t.Highlight))
+	presetSelectedStyle = presetSelectedStyle.Foreground(lipgloss.Color(t.Accent))
+	presetHintStyle = presetHintStyle.Foreground(lipgloss.Color(t.Muted))
+	detailPaneStyle = detailPaneStyle.BorderForeground(lipgloss.Color(t.Accent))
+	detailLabelStyle = detailLabelStyle.Foreground(lipgloss.Color(t.Muted))
+	summaryPanelStyle = summaryPanelStyle.BorderForeground(lipgloss.Color(t.Muted))
+	summaryLabelStyle = summaryLabelStyle.Foreground(lipgloss.Color(t.Muted))
+
+	successStyle = successStyle.Foreground(lipgloss.Color(t.Success))
+	failureStyle = failureStyle.Foreground(lipgloss.Color(t.Error))
+	warningStyle = warningStyle.Foreground(lipgloss.Color(t.Warning))
+
+	exposureStyles[ExposureLoopback] = exposureStyles[ExposureLoopback].Foreground(lipgloss.Color(t.Loopback))
+	exposureStyles[ExposureLinkLocal] = exposureStyles[ExposureLinkLocal].Foreground(lipgloss.Color(t.LinkLocal))
+	exposureStyles[ExposurePrivate] = exposureStyles[ExposurePrivate].Foreground(lipgloss.Color(t.Private))
+	exposureStyles[ExposureWildcard] = exposureStyles[ExposureWildcard].Foreground(lipgloss.Color(t.Wildcard))
+	exposureStyles[ExposurePublic] = exposureStyles[ExposurePublic].Foreground(lipgloss.Color(t.Public))
+}
+
+// selectedRowStyle returns the style of the selected table row for the active theme.
+func selectedRowStyle(style lipgloss.Style) lipgloss.Style {
+	if activeTheme.Selected == "" {
+		return style.Reverse(true)
+	}
+
+	return style.Foreground(lipgloss.Color(activeTheme.Selected))
+}
+
+// colorMode is the value of the -color flag: "auto", "always" or "never".
+// Setting it switches the color profile of all the output.
+type colorMode string
+
+const (
+	colorAuto   colorMode = "auto"
+	colorAlways colorMode = "always"
+	colorNever  colorMode = "never"
+)
+
+func (c *colorMode) String() string { return string(*c) }
+
+func (c *colorMode) Set(value string) error {
+	switch mode := colorMode(value); mode {
+	case colorAuto:
+		// Colors are detected from the terminal and the NO_COLOR and CLICOLOR variables.
+	case colorAlways:
+		profile := termenv.ANSI256
+		if strings.Contains(os.Getenv("COLORTERM"), "truecolor") || strings.Contains(os.Getenv("COLORTERM"), "24bit") {
+			profile = termenv.TrueColor
+		}
+		lipgloss.SetColorProfile(profile)
+	case colorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		return fmt.Errorf("must be auto, always or never")
+	}
+
+	*c = colorMode(value)
+
+	return nil
+}
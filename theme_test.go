@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeValidate(t *testing.T) {
+	tests := map[string]struct {
+		theme   Theme
+		wantErr string
+	}{
+		"empty":         {theme: Theme{}},
+		"ansi colors":   {theme: Theme{Accent: "0", Error: "255"}},
+		"hex colors":    {theme: Theme{Accent: "#ff00AA", Public: "#000000"}},
+		"out of range":  {theme: Theme{Muted: "256"}, wantErr: `muted: invalid color "256"`},
+		"negative":      {theme: Theme{Error: "-1"}, wantErr: `error: invalid color "-1"`},
+		"hex without #": {theme: Theme{Accent: "ff00aa"}, wantErr: `accent: invalid color "ff00aa"`},
+		"color name":    {theme: Theme{Private: "red"}, wantErr: `private: invalid color "red"`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.theme.validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	custom := map[string]Theme{
+		"ocean":  {Base: "light", Accent: "33"},
+		"deep":   {Base: "ocean", Error: "#ff0000"},
+		"loop":   {Base: "cycle"},
+		"cycle":  {Base: "loop"},
+		"broken": {Base: "light", Muted: "gray"},
+		"orphan": {Base: "missing"},
+	}
+
+	light := builtinThemes["light"]
+
+	tests := map[string]struct {
+		theme   Theme
+		custom  map[string]Theme
+		want    Theme
+		wantErr string
+	}{
+		"built-in": {
+			theme: Theme{Base: "light"},
+			want:  light,
+		},
+		"built-in with overrides": {
+			theme: Theme{Base: "high-contrast", Accent: "#123456"},
+			want:  builtinThemes["high-contrast"].merge(Theme{Accent: "#123456"}),
+		},
+		"custom chain": {
+			theme:  Theme{Base: "deep", Warning: "100"},
+			custom: custom,
+			want:   light.merge(Theme{Accent: "33", Error: "#ff0000", Warning: "100"}),
+		},
+		"cycle": {
+			theme:   Theme{Base: "loop"},
+			custom:  custom,
+			wantErr: `theme "loop" is based on itself`,
+		},
+		"unknown base": {
+			theme:   Theme{Base: "orphan"},
+			custom:  custom,
+			wantErr: `unknown theme "missing"`,
+		},
+		"invalid color in a base": {
+			theme:   Theme{Base: "broken"},
+			custom:  custom,
+			wantErr: `muted: invalid color "gray"`,
+		},
+		"shadowed built-in": {
+			theme:   Theme{Base: "dark"},
+			custom:  map[string]Theme{"dark": {Accent: "1"}},
+			wantErr: `custom theme "dark" shadows a built-in theme`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveTheme(tc.theme, tc.custom)
+			if tc.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveTheme() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveTheme() error = %v", err)
+			}
+
+			tc.want.Base = tc.theme.Base
+			if got != tc.want {
+				t.Errorf("resolveTheme() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}